@@ -0,0 +1,186 @@
+package grafo
+
+import "iter"
+
+// FloydWarshall computes the shortest paths between all pairs of vertices.
+// Negative edges are allowed and NaN edges are skipped.
+// The number dist[v][w] is the length of a shortest path from v to w,
+// or inf if w cannot be reached from v.
+// (inf is +inf for floats and the maximum value for integers).
+// The number next[v][w] is the vertex after v on a shortest path
+// from v to w, or -1 if none exists, use NextHopPath to get the path.
+// If ok is false there is a negative cycle and the results are meaningless.
+//
+// The time complexity is O(|V|³), where |V| is the number of vertices
+// in the graph, it suits small or dense graphs.
+func FloydWarshall[T IntegerOrFloat](g Graph[T]) (dist [][]T, next [][]int, ok bool) {
+	n := g.Order()
+	dist, next = newAllPairs[T](n)
+	for v := range n {
+		for w, weight := range g.EdgesFrom(v) {
+			if isNaN(weight) {
+				continue
+			}
+			if next[v][w] == -1 || weight < dist[v][w] {
+				dist[v][w], next[v][w] = weight, w
+			}
+		}
+	}
+
+	inf := InfFor[T]()
+	for k := range n {
+		for v := range n {
+			if next[v][k] == -1 {
+				continue
+			}
+			for w := range n {
+				if next[k][w] == -1 {
+					continue
+				}
+				// Paths through an edge of inf cost cost inf,
+				// add alone would subtract from inf.
+				alt := inf
+				if dist[v][k] != inf && dist[k][w] != inf {
+					alt = add(dist[v][k], dist[k][w])
+				}
+				if next[v][w] == -1 || alt < dist[v][w] {
+					dist[v][w], next[v][w] = alt, next[v][k]
+				}
+			}
+		}
+	}
+
+	for v := range n {
+		if dist[v][v] < 0 {
+			return dist, next, false
+		}
+	}
+	return dist, next, true
+}
+
+// Johnson computes the shortest paths between all pairs of vertices.
+// It reweights the edges with BellmanFord to make them non-negative
+// and then runs Dijkstra's algorithm backward from every vertex.
+// The results have the same meaning as in FloydWarshall, except
+// that if there is a negative cycle dist and next are nil and ok
+// is false.
+//
+// The time complexity is O(|V|⋅(|E| + |V|)⋅log|V|), where |E| is the number
+// of edges and |V| the number of vertices in the graph, it suits sparse
+// graphs with negative edges.
+func Johnson[T IntegerOrFloat](g Graph[T]) (dist [][]T, next [][]int, ok bool) {
+	n := g.Order()
+	// h[v] is the length of a shortest path to v from a new vertex
+	// with edges of cost 0 to all vertices of g.
+	// The distances of a negative cycle may saturate and stop
+	// changing, but the cycle stays in the parent pointers.
+	parent, h, ok := BellmanFord(withSource[T]{g}, n)
+	if !ok || parentCycle(parent) != nil {
+		return nil, nil, false
+	}
+
+	// Search backward from each target w, a single tree of shortest
+	// paths to w keeps next consistent when there are cycles of cost 0.
+	// The edge x -> y of the transpose is the edge y -> x of g,
+	// so negating h reweights it to weight + h[y] - h[x].
+	neg := make([]T, n)
+	for v := range n {
+		neg[v] = -h[v]
+	}
+	rt := reweighted[T]{Transpose(g), neg}
+
+	dist, next = newAllPairs[T](n)
+	inf := InfFor[T]()
+	for w := range n {
+		parent, d := ShortestPaths(rt, w)
+		for v := range n {
+			if v == w || parent[v] == -1 {
+				continue
+			}
+			next[v][w] = parent[v]
+			if d[v] != inf {
+				dist[v][w] = add(add(d[v], h[w]), -h[v])
+			}
+		}
+	}
+	return dist, next, true
+}
+
+// NextHopPath returns a shortest path from v to w using the next matrix
+// of FloydWarshall or Johnson, or an empty path if w cannot be reached.
+func NextHopPath(next [][]int, v, w int) []int {
+	path := []int{}
+	if next[v][w] == -1 {
+		return path
+	}
+	path = append(path, v)
+	for v != w {
+		v = next[v][w]
+		path = append(path, v)
+	}
+	return path
+}
+
+// newAllPairs returns a n×n distance matrix, with inf everywhere except
+// the main diagonal, and a next matrix with no paths except from v to v.
+func newAllPairs[T IntegerOrFloat](n int) (dist [][]T, next [][]int) {
+	inf := InfFor[T]()
+	dist, next = make([][]T, n), make([][]int, n)
+	for v := range n {
+		dist[v], next[v] = make([]T, n), make([]int, n)
+		for w := range n {
+			dist[v][w], next[v][w] = inf, -1
+		}
+		dist[v][v], next[v][v] = 0, v
+	}
+	return dist, next
+}
+
+// withSource is g with a new vertex, g.Order(),
+// with edges of cost 0 to all vertices of g.
+type withSource[T IntegerOrFloat] struct {
+	g Graph[T]
+}
+
+func (s withSource[T]) Order() int { return s.g.Order() + 1 }
+
+func (s withSource[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	if v < s.g.Order() {
+		return s.g.EdgesFrom(v)
+	}
+	return func(yield func(int, T) bool) {
+		for w := range v {
+			if !yield(w, 0) {
+				return
+			}
+		}
+	}
+}
+
+// reweighted is g with the cost of each edge v -> w
+// changed to weight + h[v] - h[w].
+type reweighted[T IntegerOrFloat] struct {
+	g Graph[T]
+	h []T
+}
+
+func (r reweighted[T]) Order() int { return r.g.Order() }
+
+func (r reweighted[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	inf := InfFor[T]()
+	return func(yield func(int, T) bool) {
+		for w, weight := range r.g.EdgesFrom(v) {
+			if weight != inf && !isNaN(weight) {
+				weight = add(add(weight, r.h[v]), -r.h[w])
+				// The new cost is non-negative, except
+				// for floating-point rounding errors.
+				if weight < 0 {
+					weight = 0
+				}
+			}
+			if !yield(w, weight) {
+				return
+			}
+		}
+	}
+}
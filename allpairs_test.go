@@ -0,0 +1,158 @@
+package grafo
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var allPairsFuncs = []struct {
+	name string
+	f    func(Graph[int]) ([][]int, [][]int, bool)
+}{
+	{"FloydWarshall", FloydWarshall[int]},
+	{"Johnson", Johnson[int]},
+}
+
+func TestAllPairs(t *testing.T) {
+	inf := InfFor[int]()
+
+	// Exemplo G of TestBellmanFord.
+	g := NewMutable[int](6)
+	g.Add(0, 1, 41)
+	g.Add(0, 5, 29)
+	g.Add(1, 2, 51)
+	g.Add(1, 4, 32)
+	g.Add(2, 3, 50)
+	g.Add(3, 0, 45)
+	g.Add(3, 5, -38)
+	g.Add(4, 2, 32)
+	g.Add(4, 3, 36)
+	g.Add(5, 1, -29)
+	g.Add(5, 4, 21)
+
+	// A negative cycle 1 -> 2 -> 1.
+	cycle := NewMutable[int](3)
+	cycle.Add(0, 1, 1)
+	cycle.Add(1, 2, -2)
+	cycle.Add(2, 1, 1)
+
+	// 0 -> 1 -> 2 costs inf and 3 is unreachable.
+	unreachable := NewMutable[int](4)
+	unreachable.Add(0, 1, inf)
+	unreachable.Add(1, 2, -1)
+	unreachable.Add(3, 2, 1)
+
+	for _, tt := range allPairsFuncs {
+		t.Run(tt.name, func(t *testing.T) {
+			dist, next, ok := tt.f(g)
+			if !ok {
+				t.Fatalf("%s got ok = false", tt.name)
+			}
+			if diff := cmp.Diff(dist[4], []int{81, -31, 20, 36, 0, -2}); diff != "" {
+				t.Errorf("%s->dist[4] %s", tt.name, diff)
+			}
+			if diff := cmp.Diff(NextHopPath(next, 4, 2), []int{4, 3, 5, 1, 2}); diff != "" {
+				t.Errorf("NextHopPath(4, 2) %s", diff)
+			}
+
+			if _, _, ok := tt.f(cycle); ok {
+				t.Errorf("%s got ok = true with a negative cycle", tt.name)
+			}
+
+			dist, next, ok = tt.f(unreachable)
+			if !ok {
+				t.Fatalf("%s got ok = false", tt.name)
+			}
+			if diff := cmp.Diff(dist[0], []int{0, inf, inf, inf}); diff != "" {
+				t.Errorf("%s->dist[0] %s", tt.name, diff)
+			}
+			if diff := cmp.Diff(NextHopPath(next, 0, 2), []int{0, 1, 2}); diff != "" {
+				t.Errorf("NextHopPath(0, 2) %s", diff)
+			}
+			if diff := cmp.Diff(NextHopPath(next, 0, 3), []int{}); diff != "" {
+				t.Errorf("NextHopPath(0, 3) %s", diff)
+			}
+		})
+	}
+}
+
+func TestAllPairsRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 50 {
+		n := 1 + rnd.IntN(30)
+		g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+		// Reweighting the edges with a potential keeps the length
+		// of the cycles, so g has negative edges but no negative cycles.
+		p := make([]int, n)
+		for v := range p {
+			p[v] = rnd.IntN(40)
+		}
+		for v := range n {
+			for w, weight := range g.EdgesFrom(v) {
+				g.Add(v, w, weight+p[v]-p[w])
+			}
+		}
+
+		for _, tt := range allPairsFuncs {
+			dist, next, ok := tt.f(g)
+			if !ok {
+				t.Fatalf("%s(%s) got ok = false", tt.name, String(g))
+			}
+			for v := range n {
+				_, want, _ := BellmanFord(g, v)
+				if diff := cmp.Diff(dist[v], want); diff != "" {
+					t.Fatalf("%s(%s)->dist[%d] %s", tt.name, String(g), v, diff)
+				}
+				for w := range n {
+					path := NextHopPath(next, v, w)
+					if dist[v][w] == InfFor[int]() {
+						if len(path) != 0 {
+							t.Fatalf("%s(%s) got path %v to an unreachable vertex", tt.name, String(g), path)
+						}
+						continue
+					}
+					if path[0] != v || path[len(path)-1] != w || pathLength(g, path) != dist[v][w] {
+						t.Fatalf("%s(%s) got path %v, want a path of length %d", tt.name, String(g), path, dist[v][w])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAllPairsNegativeCycles(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 100 {
+		n := 1 + rnd.IntN(10)
+		g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+		for v := range n {
+			for w, weight := range g.EdgesFrom(v) {
+				g.Add(v, w, weight-5)
+			}
+		}
+		_, _, fwOK := FloydWarshall(g)
+		dist, next, jOK := Johnson(g)
+		if fwOK != jOK {
+			t.Fatalf("FloydWarshall(%s) got ok = %v, Johnson got ok = %v", String(g), fwOK, jOK)
+		}
+		if !jOK && (dist != nil || next != nil) {
+			t.Fatalf("Johnson(%s) got non-nil results with a negative cycle", String(g))
+		}
+	}
+}
+
+func TestAllPairsSaturatedNegativeCycle(t *testing.T) {
+	// The distances saturate at -128 before the cycle is detected.
+	g := NewMutable[int8](3)
+	g.Add(0, 1, -100)
+	g.Add(1, 2, -100)
+	g.Add(2, 1, -100)
+	if _, _, ok := FloydWarshall(g); ok {
+		t.Errorf("FloydWarshall got ok = true, want false")
+	}
+	if dist, next, ok := Johnson(g); ok || dist != nil || next != nil {
+		t.Errorf("Johnson got ok = %v, dist = %v, want false and nil", ok, dist)
+	}
+}
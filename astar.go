@@ -0,0 +1,88 @@
+package grafo
+
+// AStar computes a shortest path from v to w using the A* algorithm.
+// The heuristic h(u) estimates the length of a shortest path from u to w,
+// it guides the search towards w, so fewer vertices are visited than
+// in ShortestPath.
+//
+// The path is a shortest path if h never overestimates the distance
+// to w (h is admissible). If h is also consistent, h(u) <= weight(u, x) + h(x)
+// for every edge u -> x, each vertex is visited at most once.
+// Negative and NaN values of h are treated as zero, with h(u) = 0
+// for every u AStar is equivalent to ShortestPath.
+//
+// Only edges with non-negative and non-NaN costs are included.
+// The number dist is the length of the path, or inf if w cannot be reached.
+// (inf is +inf for floats and the maximum value for integers).
+func AStar[T IntegerOrFloat](g Graph[T], v, w int, h func(v int) T) (path []int, dist T) {
+	n := g.Order()
+	inf := InfFor[T]()
+	// dist[u] is the length of the shortest known path from v to u
+	// and f[u] = dist[u] + h(u) is its priority in the queue.
+	distances := make([]T, n)
+	f := make([]T, n)
+	parent := make([]int, n)
+	for i := range distances {
+		distances[i], f[i], parent[i] = inf, inf, -1
+	}
+	distances[v] = 0
+	f[v] = estimate(0, h(v))
+	parent[v] = v
+
+	Q := emptyPrioQueue(f)
+	Q.Push(v)
+
+	source, target := v, w
+	for Q.Len() > 0 {
+		v = Q.Pop()
+		if v == target {
+			break
+		}
+		for w, weight := range g.EdgesFrom(v) {
+			// Skip NaN and negative edges.
+			if isNaN(weight) || weight < 0 {
+				continue
+			}
+			alt := distances[v] + weight
+			// alt < dist[v] is an int overflow,
+			// if there is an overflow the distance is bigger
+			// than inf so treat as inf.
+			if alt < distances[v] {
+				alt = inf
+			}
+			switch {
+			case parent[w] == -1:
+				distances[w], parent[w] = alt, v
+				f[w] = estimate(alt, h(w))
+				Q.Push(w)
+			case alt < distances[w]:
+				distances[w], parent[w] = alt, v
+				f[w] = estimate(alt, h(w))
+				// With an inconsistent heuristic a visited
+				// vertex may be reached by a shorter path.
+				if Q.Contains(w) {
+					Q.Fix(w)
+				} else {
+					Q.Push(w)
+				}
+			}
+		}
+	}
+
+	parent[source] = -1
+	return pathTo(parent, distances, target)
+}
+
+// estimate returns dist + h, the estimated length of a path
+// through a vertex at distance dist from the source.
+func estimate[T IntegerOrFloat](dist, h T) T {
+	if isNaN(h) || h < 0 {
+		h = 0
+	}
+	f := dist + h
+	// Treat overflows as inf.
+	if f < dist {
+		return InfFor[T]()
+	}
+	return f
+}
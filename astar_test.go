@@ -0,0 +1,160 @@
+package grafo
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func zero[T IntegerOrFloat](int) T { return 0 }
+
+// gridGraph returns a rows x cols grid with random weights in [1, maxWeight]
+// between neighbors, and the Manhattan distance to target as heuristic.
+func gridGraph(rows, cols, maxWeight, target int, rnd *rand.Rand) (*Mutable[int], func(v int) int) {
+	g := NewMutable[int](rows * cols)
+	for r := range rows {
+		for c := range cols {
+			v := r*cols + c
+			if c+1 < cols {
+				g.AddBoth(v, v+1, 1+rnd.IntN(maxWeight))
+			}
+			if r+1 < rows {
+				g.AddBoth(v, v+cols, 1+rnd.IntN(maxWeight))
+			}
+		}
+	}
+	tr, tc := target/cols, target%cols
+	h := func(v int) int {
+		r, c := v/cols, v%cols
+		return max(r-tr, tr-r) + max(c-tc, tc-c)
+	}
+	return g, h
+}
+
+func TestAStar(t *testing.T) {
+	t.Run("ime", func(t *testing.T) {
+		g := NewMutable[int](6)
+		g.Add(0, 1, 10)
+		g.Add(0, 2, 20)
+		g.Add(1, 3, 70)
+		g.Add(1, 4, 80)
+		g.Add(2, 3, 50)
+		g.Add(2, 4, 60)
+		g.Add(3, 1, 0)
+		g.Add(3, 5, 10)
+		g.Add(4, 5, 10)
+
+		path, dist := AStar(g, 0, 5, zero[int])
+		if diff := cmp.Diff(path, []int{0, 2, 3, 5}); diff != "" {
+			t.Errorf("AStar->path %s", diff)
+		}
+		if dist != 80 {
+			t.Errorf("AStar->dist got %d, want 80", dist)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		g := NewMutable[float64](3)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, -1)
+		g.Add(0, 2, InfFor[float64]()-InfFor[float64]()) // NaN.
+
+		path, dist := AStar(g, 0, 2, zero[float64])
+		if diff := cmp.Diff(path, []int{}); diff != "" {
+			t.Errorf("AStar->path %s", diff)
+		}
+		if dist != InfFor[float64]() {
+			t.Errorf("AStar->dist got %v, want inf", dist)
+		}
+	})
+
+	t.Run("same vertex", func(t *testing.T) {
+		g := NewMutable[uint8](1)
+		path, dist := AStar(g, 0, 0, zero[uint8])
+		if diff := cmp.Diff(path, []int{0}); diff != "" {
+			t.Errorf("AStar->path %s", diff)
+		}
+		if dist != 0 {
+			t.Errorf("AStar->dist got %v, want 0", dist)
+		}
+	})
+
+	t.Run("uint inf + 1", func(t *testing.T) {
+		inf := InfFor[uint]()
+		g := NewMutable[uint](3)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, inf)
+
+		path, dist := AStar(g, 0, 2, func(int) uint { return inf })
+		if diff := cmp.Diff(path, []int{0, 1, 2}); diff != "" {
+			t.Errorf("AStar->path %s", diff)
+		}
+		if dist != inf {
+			t.Errorf("AStar->dist got %v, want inf", dist)
+		}
+	})
+
+	t.Run("inconsistent heuristic", func(t *testing.T) {
+		// h is admissible but not consistent, so vertex 2 is
+		// visited first through the longer path 0 -> 1 -> 2.
+		g := NewMutable[int](5)
+		g.Add(0, 1, 1)
+		g.Add(0, 3, 1)
+		g.Add(1, 2, 3)
+		g.Add(3, 2, 1)
+		g.Add(2, 4, 5)
+		h := []int{0, 3, 5, 6, 0}
+		path, dist := AStar(g, 0, 4, func(v int) int { return h[v] })
+		if diff := cmp.Diff(path, []int{0, 3, 2, 4}); diff != "" {
+			t.Errorf("AStar->path %s", diff)
+		}
+		if dist != 7 {
+			t.Errorf("AStar->dist got %d, want 7", dist)
+		}
+	})
+
+	t.Run("random grids", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 20 {
+			rows, cols := 1+rnd.IntN(20), 1+rnd.IntN(20)
+			v, w := rnd.IntN(rows*cols), rnd.IntN(rows*cols)
+			g, h := gridGraph(rows, cols, 10, w, rnd)
+			_, want := ShortestPath(g, v, w)
+			path, dist := AStar(g, v, w, h)
+			if dist != want {
+				t.Fatalf("AStar(%d, %d) got dist %d, want %d", v, w, dist, want)
+			}
+			if got := pathLength(g, path); got != dist {
+				t.Fatalf("AStar(%d, %d) got path %v of length %d, want %d", v, w, path, got, dist)
+			}
+		}
+	})
+}
+
+func pathLength(g *Mutable[int], path []int) int {
+	length := 0
+	for i := 1; i < len(path); i++ {
+		length += g.edges[path[i-1]][path[i]]
+	}
+	return length
+}
+
+func BenchmarkAStar(b *testing.B) {
+	// From the middle of the left side to the middle of the right
+	// side of a grid with unit weights.
+	const rows, cols = 300, 300
+	source, target := rows/2*cols, rows/2*cols+cols-1
+	g, h := gridGraph(rows, cols, 1, target, rand.New(rand.NewPCG(0, 1)))
+	s := Sort(g)
+	b.Run("AStar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = AStar(s, source, target, h)
+		}
+	})
+	b.Run("ShortestPath", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = ShortestPath(s, source, target)
+		}
+	})
+}
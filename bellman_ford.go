@@ -1,11 +1,19 @@
 package grafo
 
+import "slices"
+
 // BellmanFord calculate the shortest path from v to all other vertices.
 // The function returns the a slice of parent, a slice of dist containing the dists
 // between v and the vertex and return ok if there isn't a negative cycle.
 //
 // The algorithm skip NaN weighted edges.
 func BellmanFord[T IntegerOrFloat](g Graph[T], v int) (parent []int, dist []T, ok bool) {
+	return bellmanFord(g, v, func([]int) bool { return true })
+}
+
+// bellmanFord runs BellmanFord, after each pass of relaxations
+// from the n-th on it stops with ok = false if stop(parent) is true.
+func bellmanFord[T IntegerOrFloat](g Graph[T], v int, stop func(parent []int) bool) (parent []int, dist []T, ok bool) {
 	// Adapted from https://www.ime.usp.br/~pf/algoritmos_para_grafos/aulas/bellman-ford.html.
 
 	n := g.Order()
@@ -53,7 +61,7 @@ func BellmanFord[T IntegerOrFloat](g Graph[T], v int) (parent []int, dist []T, o
 			}
 
 			k++
-			if k >= n {
+			if k >= n && stop(parent) {
 				ok = false // Negative cycle.
 				break
 			}
@@ -68,6 +76,109 @@ func BellmanFord[T IntegerOrFloat](g Graph[T], v int) (parent []int, dist []T, o
 	return parent, dist, ok
 }
 
+// NegativeCycle returns the vertices of a negative cycle reachable from v,
+// or nil if there is none. The cycle starts at its smallest vertex and
+// follows the edges cycle[i] -> cycle[i+1], closing with the edge
+// cycle[len(cycle)-1] -> cycle[0].
+//
+// The algorithm skip NaN weighted edges.
+func NegativeCycle[T IntegerOrFloat](g Graph[T], v int) (cycle []int) {
+	// Relaxations don't stop with a negative cycle, keep
+	// relaxing until it appears in the parent pointers,
+	// a cycle of parent pointers always has negative cost.
+	bellmanFord(g, v, func(parent []int) bool {
+		cycle = parentCycle(parent)
+		return cycle != nil
+	})
+	return cycle
+}
+
+// parentCycle returns a cycle of parent pointers, or nil if there is none.
+func parentCycle(parent []int) []int {
+	roots := cycleRoots(parent)
+	if len(roots) == 0 {
+		return nil
+	}
+	// The parent pointers go through the cycle backwards.
+	u := roots[0]
+	cycle := []int{u}
+	first := 0
+	for x := parent[u]; x != u; x = parent[x] {
+		if x < cycle[first] {
+			first = len(cycle)
+		}
+		cycle = append(cycle, x)
+	}
+	slices.Reverse(cycle)
+	first = len(cycle) - 1 - first
+	return append(cycle[first:], cycle[:first]...)
+}
+
+// cycleRoots returns a vertex of each cycle of parent pointers.
+func cycleRoots(parent []int) (roots []int) {
+	// walk[u] is 1 + the start of the walk that visited u.
+	walk := make([]int, len(parent))
+	for s := range parent {
+		u := s
+		for u != -1 && walk[u] == 0 {
+			walk[u] = s + 1
+			u = parent[u]
+		}
+		if u != -1 && walk[u] == s+1 {
+			roots = append(roots, u)
+		}
+	}
+	return roots
+}
+
+// NegativeInf marks the vertices reachable from v whose distance from v
+// is -inf, because a negative cycle reachable from v reaches them.
+// The number negInf[w] is true if the distance to w is -inf.
+//
+// The algorithm skip NaN weighted edges.
+func NegativeInf[T IntegerOrFloat](g Graph[T], v int) (negInf []bool) {
+	n := g.Order()
+	negInf = make([]bool, n)
+	parent, dist, ok := BellmanFord(g, v)
+	if ok {
+		return negInf
+	}
+
+	// After n passes the distances not affected by negative cycles
+	// are final, so an edge u -> w can be relaxed only if u is
+	// reached by a negative cycle. Every negative cycle has such an
+	// edge unless its distances saturated, then it stays in the
+	// parent pointers. Mark all vertices reachable from them.
+	inf := InfFor[T]()
+	Q := newQueue(n)
+	for _, u := range cycleRoots(parent) {
+		negInf[u] = true
+		Q.Push(u)
+	}
+	for u := range n {
+		if dist[u] == inf || negInf[u] {
+			continue
+		}
+		for w, weight := range g.EdgesFrom(u) {
+			if !isNaN(weight) && add(dist[u], weight) < dist[w] {
+				negInf[u] = true
+				Q.Push(u)
+				break
+			}
+		}
+	}
+	for Q.Len() > 0 {
+		u := Q.Pop()
+		for w, weight := range g.EdgesFrom(u) {
+			if !isNaN(weight) && !negInf[w] {
+				negInf[w] = true
+				Q.Push(w)
+			}
+		}
+	}
+	return negInf
+}
+
 // add add two numbers and check for overflow and
 // underflow, if overflow occurs add return positive
 // inf, if underflows it returns negative inf.
@@ -1,6 +1,7 @@
 package grafo
 
 import (
+	"math/rand/v2"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -77,3 +78,110 @@ func TestBellmanFord(t *testing.T) {
 		})
 	}
 }
+
+func TestNegativeCycle(t *testing.T) {
+	g := NewMutable[int](7)
+	g.Add(0, 1, 1)
+	g.Add(1, 2, -2)
+	g.Add(2, 3, -1)
+	g.Add(3, 1, 1)
+	g.Add(3, 4, 1)
+	g.Add(5, 6, -1)
+	g.Add(6, 5, -1)
+
+	tests := []struct {
+		name       string
+		v          int
+		g          Graph[int]
+		wantCycle  []int
+		wantNegInf []bool
+	}{
+		{"cycle", 0, g, []int{1, 2, 3}, []bool{false, true, true, true, true, false, false}},
+		{"loop", 5, g, []int{5, 6}, []bool{false, false, false, false, false, true, true}},
+		{"unreachable", 4, g, nil, []bool{false, false, false, false, false, false, false}},
+		{"no cycle", 0, NewMutable[int](2), nil, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(NegativeCycle(tt.g, tt.v), tt.wantCycle); diff != "" {
+				t.Errorf("NegativeCycle %s", diff)
+			}
+			if diff := cmp.Diff(NegativeInf(tt.g, tt.v), tt.wantNegInf); diff != "" {
+				t.Errorf("NegativeInf %s", diff)
+			}
+		})
+	}
+}
+
+func TestNegativeInfSaturated(t *testing.T) {
+	// The distances saturate at -128, no edge can be relaxed
+	// after the n-th pass.
+	g := NewMutable[int8](3)
+	g.Add(0, 1, -100)
+	g.Add(1, 2, -100)
+	g.Add(2, 1, -100)
+
+	if diff := cmp.Diff(NegativeCycle(g, 0), []int{1, 2}); diff != "" {
+		t.Errorf("NegativeCycle %s", diff)
+	}
+	if diff := cmp.Diff(NegativeInf(g, 0), []bool{false, true, true}); diff != "" {
+		t.Errorf("NegativeInf %s", diff)
+	}
+}
+
+func TestNegativeCycleRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 200 {
+		n := 1 + rnd.IntN(10)
+		g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+		for v := range n {
+			for w, weight := range g.EdgesFrom(v) {
+				g.Add(v, w, weight-4)
+			}
+		}
+		v := rnd.IntN(n)
+
+		_, _, ok := BellmanFord(g, v)
+		cycle := NegativeCycle(g, v)
+		if ok != (cycle == nil) {
+			t.Fatalf("NegativeCycle(%s, %d) got %v, BellmanFord got ok = %v", String(g), v, cycle, ok)
+		}
+		cost := 0
+		for i, u := range cycle {
+			w := cycle[(i+1)%len(cycle)]
+			if _, ok := g.edges[u][w]; !ok {
+				t.Fatalf("NegativeCycle(%s, %d) got %v, %d -> %d is not an edge", String(g), v, cycle, u, w)
+			}
+			cost += g.Weight(u, w)
+		}
+		if cost >= 0 && cycle != nil {
+			t.Fatalf("NegativeCycle(%s, %d) got %v of cost %d", String(g), v, cycle, cost)
+		}
+
+		// A vertex is at distance -inf if it is reachable from
+		// a vertex in a negative cycle reachable from v.
+		dist, _, _ := FloydWarshall(g)
+		want := make([]bool, n)
+		for c := range n {
+			if dist[c][c] >= 0 || (c != v && !reachable(g, v, c)) {
+				continue
+			}
+			want[c] = true
+			for e := range BFS(g, c) {
+				want[e.W] = true
+			}
+		}
+		if diff := cmp.Diff(NegativeInf(g, v), want); diff != "" {
+			t.Fatalf("NegativeInf(%s, %d) %s", String(g), v, diff)
+		}
+	}
+}
+
+func reachable[T any](g Graph[T], v, w int) bool {
+	for e := range BFS(g, v) {
+		if e.W == w {
+			return true
+		}
+	}
+	return false
+}
@@ -67,6 +67,55 @@ func BenchmarkShortestPath(b *testing.B) {
 	}
 }
 
+func BenchmarkCH(b *testing.B) {
+	b.Run("NewCH", func(b *testing.B) {
+		for range b.N {
+			_ = NewCH(dimacsG)
+		}
+	})
+	ch := NewCH(dimacsG)
+	b.Run("ShortestPath", func(b *testing.B) {
+		for range b.N {
+			_, _ = ch.ShortestPath(0, dimacsG.Order()-1)
+		}
+	})
+}
+
+func BenchmarkMaxFlow(b *testing.B) {
+	g := generateRandom(500, 5000, 100, rand.New(rand.NewPCG(0, 1)))
+	for _, a := range []struct {
+		name string
+		a    MaxFlowAlgorithm
+	}{
+		{"EdmondsKarp", EdmondsKarp},
+		{"Dinic", Dinic},
+		{"PushRelabel", PushRelabel},
+	} {
+		b.Run(a.name, func(b *testing.B) {
+			for range b.N {
+				_, _ = MaxFlow(g, 0, g.Order()-1, WithMaxFlowAlgorithm(a.a))
+			}
+		})
+	}
+}
+
+func BenchmarkMinCostFlow(b *testing.B) {
+	g, supply := randomCostFlow(500, 5000, 100, rand.New(rand.NewPCG(0, 1)))
+	for _, a := range []struct {
+		name string
+		a    MinCostFlowAlgorithm
+	}{
+		{"SuccessiveShortestPaths", SuccessiveShortestPaths},
+		{"NetworkSimplex", NetworkSimplex},
+	} {
+		b.Run(a.name, func(b *testing.B) {
+			for range b.N {
+				_, _, _ = MinCostFlow(g, supply, WithMinCostFlowAlgorithm(a.a))
+			}
+		})
+	}
+}
+
 func BenchmarkStrongComponents(b *testing.B) {
 	benchmarks := []struct {
 		name string
@@ -161,3 +210,17 @@ func readDIMACS(fname string) *Immutable[int] {
 	}
 	return Sort(g)
 }
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	data, err := dimacsG.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		var g Immutable[int]
+		if err := g.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package grafo
 
-import "iter"
+import (
+	"iter"
+	"slices"
+)
 
 // Edge is a directed graph edge V -[Weight]-> W.
 type Edge[T any] struct {
@@ -34,3 +37,85 @@ func BFS[T any](g Graph[T], v int) iter.Seq[Edge[T]] {
 		}
 	}
 }
+
+// BFSPaths computes the paths with the fewest edges from the sources
+// to all other vertices, using Breadth First Search.
+// The number parent[w] is the predecessor of w on a shortest path from
+// the nearest source to w, or -1 if w is a source or cannot be reached.
+// The number dist[w] is the number of edges of a shortest path from the
+// nearest source to w, or -1 if w cannot be reached.
+//
+// The time complexity is O(|E| + |V|), where |E| is the number of edges
+// and |V| the number of vertices in the graph.
+func BFSPaths[T any](g Graph[T], sources ...int) (parent []int, dist []int) {
+	return BFSPathsDepth(g, g.Order(), sources...)
+}
+
+// BFSPathsDepth is like BFSPaths but stops after depth edges from
+// the sources, the vertices farther than depth are unreachable.
+// No vertex is reachable if depth is negative.
+func BFSPathsDepth[T any](g Graph[T], depth int, sources ...int) (parent []int, dist []int) {
+	n := g.Order()
+	parent, dist = make([]int, n), make([]int, n)
+	for i := range n {
+		parent[i], dist[i] = -1, -1
+	}
+	if depth < 0 {
+		return parent, dist
+	}
+	bfs(g, depth, sources, parent, dist, func([]int) bool { return true })
+	return parent, dist
+}
+
+// BFSLevels returns an iterator of the vertices reachable from the
+// sources grouped by their number of edges from the nearest source,
+// using Breadth First Search. Each iteration returns a level, starting
+// from 0 with the sources, and its vertices. The slices of vertices
+// share memory and must not be modified.
+func BFSLevels[T any](g Graph[T], sources ...int) iter.Seq2[int, []int] {
+	return func(yield func(level int, vertices []int) bool) {
+		dist := make([]int, g.Order())
+		for i := range dist {
+			dist[i] = -1
+		}
+		level := 0
+		bfs(g, g.Order(), sources, nil, dist, func(vertices []int) bool {
+			level++
+			return yield(level-1, vertices)
+		})
+	}
+}
+
+// bfs runs a Breadth First Search from the sources, up to depth edges,
+// and calls yield with the vertices of each level until it returns false.
+// The dist of the vertices not visited must be -1, the parent
+// is optional.
+func bfs[T any](g Graph[T], depth int, sources []int, parent, dist []int, yield func(vertices []int) bool) {
+	order := make([]int, 0, len(sources))
+	for _, v := range sources {
+		if dist[v] == -1 {
+			dist[v] = 0
+			order = append(order, v)
+		}
+	}
+	start := 0
+	for d := 0; start < len(order); d++ {
+		end := len(order)
+		if !yield(slices.Clip(order[start:end])) || d == depth {
+			return
+		}
+		for _, v := range order[start:end] {
+			for w := range g.EdgesFrom(v) {
+				if dist[w] != -1 {
+					continue
+				}
+				dist[w] = d + 1
+				if parent != nil {
+					parent[w] = v
+				}
+				order = append(order, w)
+			}
+		}
+		start = end
+	}
+}
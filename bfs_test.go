@@ -2,7 +2,11 @@ package grafo
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestBFS(t *testing.T) {
@@ -27,6 +31,109 @@ func TestBFS(t *testing.T) {
 	})
 }
 
+func TestBFSPaths(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(500)
+			g := generateRandomWithRand(n, rnd.IntN(3*n), func() int { return 1 }, rnd)
+			sources := make([]int, 1+rnd.IntN(3))
+			for i := range sources {
+				sources[i] = rnd.IntN(n)
+			}
+
+			want := make([]int, n)
+			for i := range want {
+				want[i] = -1
+			}
+			for _, v := range sources {
+				_, dist := ShortestPaths(g, v)
+				for w, d := range dist {
+					if d != InfFor[int]() && (want[w] == -1 || d < want[w]) {
+						want[w] = d
+					}
+				}
+			}
+
+			parent, dist := BFSPaths(g, sources...)
+			if diff := cmp.Diff(dist, want); diff != "" {
+				t.Fatalf("BFSPaths(%v) dist %s", sources, diff)
+			}
+			for w, p := range parent {
+				switch {
+				case dist[w] <= 0 && p != -1:
+					t.Fatalf("BFSPaths(%v) parent[%d] = %d, want -1", sources, w, p)
+				case dist[w] > 0 && (dist[p] != dist[w]-1 || !hasEdge(g, p, w)):
+					t.Fatalf("BFSPaths(%v) parent[%d] = %d is not on a shortest path", sources, w, p)
+				}
+			}
+
+			for level, vertices := range BFSLevels(g, sources...) {
+				for _, w := range vertices {
+					if dist[w] != level {
+						t.Fatalf("BFSLevels(%v) level %d has %d, want level %d", sources, level, w, dist[w])
+					}
+				}
+			}
+
+			depth := rnd.IntN(5) - 1
+			_, dist = BFSPathsDepth(g, depth, sources...)
+			for w := range want {
+				if want[w] > depth {
+					want[w] = -1
+				}
+			}
+			if diff := cmp.Diff(dist, want); diff != "" {
+				t.Fatalf("BFSPathsDepth(%d, %v) dist %s", depth, sources, diff)
+			}
+		}
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		g := NewMutable[struct{}](7)
+		wt := struct{}{}
+		g.Add(0, 1, wt)
+		g.Add(0, 2, wt)
+		g.Add(1, 3, wt)
+		g.Add(2, 3, wt)
+		g.Add(3, 4, wt)
+		g.Add(5, 4, wt)
+
+		var got [][]int
+		for level, vertices := range BFSLevels(g, 0, 0) {
+			if level != len(got) {
+				t.Fatalf("BFSLevels level %d, want %d", level, len(got))
+			}
+			got = append(got, slices.Sorted(slices.Values(vertices)))
+		}
+		want := [][]int{{0}, {1, 2}, {3}, {4}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("BFSLevels %s", diff)
+		}
+
+		parent, dist := BFSPathsDepth(g, 2, 0, 5)
+		if diff := cmp.Diff(dist, []int{0, 1, 1, 2, 1, 0, -1}); diff != "" {
+			t.Errorf("BFSPathsDepth->dist %s", diff)
+		}
+		// 3 can be reached from 1 or 2.
+		if parent[3] == 2 {
+			parent[3] = 1
+		}
+		if diff := cmp.Diff(parent, []int{-1, 0, 0, 1, 5, -1, -1}); diff != "" {
+			t.Errorf("BFSPathsDepth->parent %s", diff)
+		}
+	})
+}
+
+func hasEdge[T any](g Graph[T], v, w int) bool {
+	for x := range g.EdgesFrom(v) {
+		if x == w {
+			return true
+		}
+	}
+	return false
+}
+
 func visitedOrder(visited []int, before []int, after []int) error {
 	a := make(map[int]struct{})
 	b := make(map[int]struct{})
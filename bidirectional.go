@@ -0,0 +1,139 @@
+package grafo
+
+// ShortestPathBidirectional computes a shortest path from v to w
+// running Dijkstra's algorithm forward from v in g and backward from w
+// in r, the reverse graph of g, until the searches meet. It usually
+// visits far fewer vertices than ShortestPath, which explores all
+// vertices closer to v than w.
+//
+// If r is nil Transpose(g) is used, pass r to reuse it across queries.
+// The result has the same meaning as in ShortestPath: only edges with
+// non-negative and non-NaN costs are included and dist is the length
+// of the path, or inf if w cannot be reached.
+// (inf is +inf for floats and the maximum value for integers).
+func ShortestPathBidirectional[T IntegerOrFloat](g, r Graph[T], v, w int) (path []int, dist T) {
+	if v == w {
+		return []int{v}, 0
+	}
+	if r == nil {
+		r = Transpose(g)
+	}
+	n := g.Order()
+	inf := InfFor[T]()
+	fwd, bwd := newSearch(g, v, n), newSearch(r, w, n)
+
+	// The best path found goes through the edge a -> b of g,
+	// with a reached forward and b backward.
+	a, b := -1, -1
+	var weight T
+	best := inf
+	for fwd.Q.Len() > 0 && bwd.Q.Len() > 0 {
+		// Every path not found yet is at least as long
+		// as the sum of the minimum distances in the queues.
+		if a != -1 && addInf(fwd.min(), bwd.min()) >= best {
+			break
+		}
+		s, o, forward := fwd, bwd, true
+		if bwd.min() < fwd.min() {
+			s, o, forward = bwd, fwd, false
+		}
+		u := s.Q.Pop()
+		for x, wt := range s.g.EdgesFrom(u) {
+			if !s.relax(u, x, wt) || o.parent[x] == -1 {
+				continue
+			}
+			d := addInf(addInf(s.dist[u], wt), o.dist[x])
+			if a == -1 || d < best {
+				best, weight = d, wt
+				a, b = u, x
+				if !forward {
+					a, b = x, u
+				}
+			}
+		}
+	}
+	if a == -1 {
+		return []int{}, inf
+	}
+
+	for x := a; x != v; x = fwd.parent[x] {
+		path = append(path, x)
+	}
+	path = append(path, v)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	for x := b; x != w; x = bwd.parent[x] {
+		path = append(path, x)
+	}
+	path = append(path, w)
+	// The distance of the vertex settled last may have decreased
+	// since the meeting edge was found.
+	return path, addInf(addInf(fwd.dist[a], weight), bwd.dist[b])
+}
+
+// search holds the state of one direction of a bidirectional search.
+type search[T IntegerOrFloat] struct {
+	g      Graph[T]
+	dist   []T
+	parent []int // parent[v] is -1 if v wasn't reached.
+	Q      *prioQueue[[]T, T]
+}
+
+func newSearch[T IntegerOrFloat](g Graph[T], v, n int) *search[T] {
+	s := emptySearch(g, n)
+	s.start(v)
+	return s
+}
+
+// emptySearch returns a search that reached no vertices.
+func emptySearch[T IntegerOrFloat](g Graph[T], n int) *search[T] {
+	s := &search[T]{g: g, dist: make([]T, n), parent: make([]int, n)}
+	inf := InfFor[T]()
+	for i := range n {
+		s.dist[i], s.parent[i] = inf, -1
+	}
+	s.Q = emptyPrioQueue(s.dist)
+	return s
+}
+
+// start starts the search from v.
+func (s *search[T]) start(v int) {
+	s.dist[v], s.parent[v] = 0, v
+	s.Q.Push(v)
+}
+
+// min returns the minimum distance in the queue, or inf if it is empty.
+func (s *search[T]) min() T {
+	if s.Q.Len() == 0 {
+		return InfFor[T]()
+	}
+	return s.dist[s.Q.Peek()]
+}
+
+// relax relaxes the edge u -> x and tells if it was followed,
+// NaN and negative edges are skipped.
+func (s *search[T]) relax(u, x int, weight T) bool {
+	if isNaN(weight) || weight < 0 {
+		return false
+	}
+	alt := addInf(s.dist[u], weight)
+	switch {
+	case s.parent[x] == -1:
+		s.dist[x], s.parent[x] = alt, u
+		s.Q.Push(x)
+	case alt < s.dist[x]:
+		s.dist[x], s.parent[x] = alt, u
+		s.Q.Fix(x)
+	}
+	return true
+}
+
+// addInf returns a + b for non-negative a and b,
+// treating an integer overflow as inf.
+func addInf[T IntegerOrFloat](a, b T) T {
+	if s := a + b; s >= a {
+		return s
+	}
+	return InfFor[T]()
+}
@@ -0,0 +1,114 @@
+package grafo
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShortestPathBidirectional(t *testing.T) {
+	t.Run("ime", func(t *testing.T) {
+		g := NewMutable[int](6)
+		g.Add(0, 1, 10)
+		g.Add(0, 2, 20)
+		g.Add(1, 3, 70)
+		g.Add(1, 4, 80)
+		g.Add(2, 3, 50)
+		g.Add(2, 4, 60)
+		g.Add(3, 1, 0)
+		g.Add(3, 5, 10)
+		g.Add(4, 5, 10)
+
+		path, dist := ShortestPathBidirectional(g, nil, 0, 5)
+		if diff := cmp.Diff(path, []int{0, 2, 3, 5}); diff != "" {
+			t.Errorf("ShortestPathBidirectional->path %s", diff)
+		}
+		if dist != 80 {
+			t.Errorf("ShortestPathBidirectional->dist got %d, want 80", dist)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		g := NewMutable[float64](4)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, -1)
+		g.Add(0, 2, math.NaN())
+		g.Add(2, 3, 1)
+
+		path, dist := ShortestPathBidirectional(g, nil, 0, 3)
+		if diff := cmp.Diff(path, []int{}); diff != "" {
+			t.Errorf("ShortestPathBidirectional->path %s", diff)
+		}
+		if dist != InfFor[float64]() {
+			t.Errorf("ShortestPathBidirectional->dist got %v, want inf", dist)
+		}
+	})
+
+	t.Run("same vertex", func(t *testing.T) {
+		g := NewMutable[int](2)
+		g.Add(0, 1, 1)
+		path, dist := ShortestPathBidirectional(g, nil, 1, 1)
+		if diff := cmp.Diff(path, []int{1}); diff != "" {
+			t.Errorf("ShortestPathBidirectional->path %s", diff)
+		}
+		if dist != 0 {
+			t.Errorf("ShortestPathBidirectional->dist got %d, want 0", dist)
+		}
+	})
+
+	t.Run("int inf + 1", func(t *testing.T) {
+		inf := InfFor[int]()
+		g := NewMutable[int](4)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, inf)
+		g.Add(2, 3, 1)
+
+		path, dist := ShortestPathBidirectional(g, nil, 0, 3)
+		if diff := cmp.Diff(path, []int{0, 1, 2, 3}); diff != "" {
+			t.Errorf("ShortestPathBidirectional->path %s", diff)
+		}
+		if dist != inf {
+			t.Errorf("ShortestPathBidirectional->dist got %v, want inf", dist)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 100 {
+			n := 2 + rnd.IntN(50)
+			g := generateRandom(n, rnd.IntN(n*(n-1)/2+1), 20, rnd)
+			r := Transpose(g)
+			for range 10 {
+				v, w := rnd.IntN(n), rnd.IntN(n)
+				_, want := ShortestPath(g, v, w)
+				path, dist := ShortestPathBidirectional(g, r, v, w)
+				if dist != want {
+					t.Fatalf("ShortestPathBidirectional(%s, %d, %d) got dist %d, want %d", String(g), v, w, dist, want)
+				}
+				if dist == InfFor[int]() {
+					continue
+				}
+				if path[0] != v || path[len(path)-1] != w || pathLength(g, path) != dist {
+					t.Fatalf("ShortestPathBidirectional(%s, %d, %d) got path %v, want a path of length %d", String(g), v, w, path, dist)
+				}
+			}
+		}
+	})
+}
+
+func BenchmarkShortestPathBidirectional(b *testing.B) {
+	r := Transpose(dimacsG)
+	target := dimacsG.Order() - 1
+	b.Run("Bidirectional", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = ShortestPathBidirectional(dimacsG, r, 0, target)
+		}
+	})
+	b.Run("ShortestPath", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = ShortestPath(dimacsG, 0, target)
+		}
+	})
+}
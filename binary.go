@@ -0,0 +1,305 @@
+package grafo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+)
+
+// The binary format of an Immutable graph is:
+//
+//	magic    "GRFO"
+//	version  byte
+//	codec    byte, how the weights are encoded (see the codec constants)
+//	size     uvarint, the size of the weights, only for codecFixed
+//	n        uvarint, the number of vertices
+//	m        uvarint, the number of edges
+//	n lists  uvarint degree, followed by degree pairs of the neighbor,
+//	         as an uvarint delta from the previous neighbor, and its weight
+//	checksum CRC-32 (IEEE) of the previous bytes, little endian
+//
+// The neighbors are written sorted, so the graph is loaded without
+// sorting them again.
+const (
+	binaryMagic   = "GRFO"
+	binaryVersion = 1
+)
+
+// Weight codecs.
+const (
+	codecCustom = iota
+	codecVarint
+	codecUvarint
+	codecFixed
+)
+
+// WeightCodec encodes and decodes the weights of the edges
+// in the binary format of Immutable.
+type WeightCodec[T any] struct {
+	// Append appends the encoding of weight to b.
+	Append func(b []byte, weight T) []byte
+	// Decode decodes a weight from the start of b and returns
+	// the number of bytes read.
+	Decode func(b []byte) (weight T, n int, err error)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+//
+// Integer weights are encoded as varints and the other types with
+// a fixed size, like floats and structs of numbers, with
+// encoding/binary in little endian. Other weight types need
+// MarshalBinaryCodec.
+func (g *Immutable[T]) MarshalBinary() ([]byte, error) {
+	codec, id, size, err := defaultCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	return g.marshalBinary(codec, id, size), nil
+}
+
+// MarshalBinaryCodec is like MarshalBinary but encodes
+// the weights with codec.
+func (g *Immutable[T]) MarshalBinaryCodec(codec WeightCodec[T]) ([]byte, error) {
+	return g.marshalBinary(codec, codecCustom, 0), nil
+}
+
+func (g *Immutable[T]) marshalBinary(codec WeightCodec[T], id byte, size int) []byte {
+	m := 0
+	for _, neighbors := range g.edges {
+		m += len(neighbors)
+	}
+
+	b := appendBinaryHeader(make([]byte, 0, 16+len(g.edges)+3*m), binaryMagic, id, size)
+	b = binary.AppendUvarint(b, uint64(len(g.edges)))
+	b = binary.AppendUvarint(b, uint64(m))
+	for _, neighbors := range g.edges {
+		b = binary.AppendUvarint(b, uint64(len(neighbors)))
+		prev := 0
+		for _, e := range neighbors {
+			b = binary.AppendUvarint(b, uint64(e.vertex-prev))
+			b = codec.Append(b, e.weight)
+			prev = e.vertex
+		}
+	}
+	return appendChecksum(b)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes data written by MarshalBinary into g, replacing
+// its edges.
+func (g *Immutable[T]) UnmarshalBinary(data []byte) error {
+	codec, id, size, err := defaultCodec[T]()
+	if err != nil {
+		return err
+	}
+	return g.unmarshalBinary(data, codec, id, size)
+}
+
+// UnmarshalBinaryCodec is like UnmarshalBinary but decodes
+// the weights with codec, the data must be written by
+// MarshalBinaryCodec.
+func (g *Immutable[T]) UnmarshalBinaryCodec(data []byte, codec WeightCodec[T]) error {
+	return g.unmarshalBinary(data, codec, codecCustom, 0)
+}
+
+var errBinaryShort = errors.New("grafo: binary data too short")
+
+func (g *Immutable[T]) unmarshalBinary(data []byte, codec WeightCodec[T], id byte, size int) error {
+	r, err := newBinaryReader(data, binaryMagic, id, size)
+	if err != nil {
+		return err
+	}
+	// Each vertex and edge take at least one byte,
+	// which bounds the allocations.
+	n, err := r.uvarint(len(r.b))
+	if err != nil {
+		return err
+	}
+	m, err := r.uvarint(len(r.b))
+	if err != nil {
+		return err
+	}
+
+	edges := make([][]neighbor[T], n)
+	all := make([]neighbor[T], m)
+	for v := range edges {
+		deg, err := r.uvarint(len(all))
+		if err != nil {
+			return err
+		}
+		neighbors := all[:deg:deg]
+		all = all[deg:]
+		prev := 0
+		for i := range neighbors {
+			delta, err := r.uvarint(n - 1 - prev)
+			if err != nil {
+				return err
+			}
+			prev += delta
+			wt, err := readWeight(r, codec)
+			if err != nil {
+				return err
+			}
+			neighbors[i] = neighbor[T]{prev, wt}
+		}
+		edges[v] = neighbors
+	}
+	if len(all) > 0 {
+		return fmt.Errorf("grafo: invalid binary data: %d edges missing", len(all))
+	}
+	if err := r.end(); err != nil {
+		return err
+	}
+
+	g.edges = edges
+	g.computeStats()
+	return nil
+}
+
+// appendBinaryHeader appends the magic, version, codec and size
+// that start the binary formats.
+func appendBinaryHeader(b []byte, magic string, id byte, size int) []byte {
+	b = append(b, magic...)
+	b = append(b, binaryVersion, id)
+	if id == codecFixed {
+		b = binary.AppendUvarint(b, uint64(size))
+	}
+	return b
+}
+
+// appendChecksum appends the checksum of b that ends the binary formats.
+func appendChecksum(b []byte) []byte {
+	return binary.LittleEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
+}
+
+// binaryReader reads the body of a binary format.
+type binaryReader struct {
+	b []byte
+}
+
+// newBinaryReader checks the header and the checksum of data
+// and returns a reader of the data between them.
+func newBinaryReader(data []byte, magic string, id byte, size int) (*binaryReader, error) {
+	if len(data) < len(magic)+2+4 {
+		return nil, errBinaryShort
+	}
+	if string(data[:len(magic)]) != magic {
+		return nil, errors.New("grafo: invalid binary data")
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(sum) {
+		return nil, errors.New("grafo: binary data checksum mismatch")
+	}
+	b := body[len(magic):]
+	if b[0] != binaryVersion {
+		return nil, fmt.Errorf("grafo: unsupported binary version %d", b[0])
+	}
+	if b[1] != id {
+		return nil, fmt.Errorf("grafo: binary weight codec %d, want %d", b[1], id)
+	}
+	r := &binaryReader{b[2:]}
+	if id == codecFixed {
+		s, err := r.uvarint(len(r.b))
+		if err != nil {
+			return nil, err
+		}
+		if s != size {
+			return nil, fmt.Errorf("grafo: binary weight size %d, want %d", s, size)
+		}
+	}
+	return r, nil
+}
+
+// uvarint reads an uvarint that is at most max.
+func (r *binaryReader) uvarint(max int) (int, error) {
+	x, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		return 0, errBinaryShort
+	}
+	if x > uint64(max) {
+		return 0, fmt.Errorf("grafo: invalid binary data: %d greater than %d", x, max)
+	}
+	r.b = r.b[n:]
+	return int(x), nil
+}
+
+// end checks that all the data was read.
+func (r *binaryReader) end() error {
+	if len(r.b) > 0 {
+		return fmt.Errorf("grafo: invalid binary data: %d trailing bytes", len(r.b))
+	}
+	return nil
+}
+
+func readWeight[T any](r *binaryReader, codec WeightCodec[T]) (T, error) {
+	wt, k, err := codec.Decode(r.b)
+	if err != nil {
+		return wt, fmt.Errorf("grafo: binary weight: %w", err)
+	}
+	if k < 0 || k > len(r.b) {
+		return wt, errBinaryShort
+	}
+	r.b = r.b[k:]
+	return wt, nil
+}
+
+// defaultCodec returns the codec of the weights used by MarshalBinary,
+// its id and, for codecFixed, the size of the weights.
+func defaultCodec[T any]() (codec WeightCodec[T], id byte, size int, err error) {
+	typ := reflect.TypeFor[T]()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		codec.Append = func(b []byte, weight T) []byte {
+			return binary.AppendVarint(b, reflect.ValueOf(weight).Int())
+		}
+		codec.Decode = func(b []byte) (weight T, n int, err error) {
+			x, n := binary.Varint(b)
+			if n <= 0 {
+				return weight, 0, errBinaryShort
+			}
+			v := reflect.ValueOf(&weight).Elem()
+			if v.OverflowInt(x) {
+				return weight, 0, fmt.Errorf("%d overflows %v", x, typ)
+			}
+			v.SetInt(x)
+			return weight, n, nil
+		}
+		return codec, codecVarint, 0, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		codec.Append = func(b []byte, weight T) []byte {
+			return binary.AppendUvarint(b, reflect.ValueOf(weight).Uint())
+		}
+		codec.Decode = func(b []byte) (weight T, n int, err error) {
+			x, n := binary.Uvarint(b)
+			if n <= 0 {
+				return weight, 0, errBinaryShort
+			}
+			v := reflect.ValueOf(&weight).Elem()
+			if v.OverflowUint(x) {
+				return weight, 0, fmt.Errorf("%d overflows %v", x, typ)
+			}
+			v.SetUint(x)
+			return weight, n, nil
+		}
+		return codec, codecUvarint, 0, nil
+	}
+
+	var zero T
+	size = binary.Size(zero)
+	if size < 0 {
+		return codec, 0, 0, fmt.Errorf("grafo: weight type %v has no fixed size, use a WeightCodec", typ)
+	}
+	codec.Append = func(b []byte, weight T) []byte {
+		// The size was checked, so Append can't fail.
+		b, _ = binary.Append(b, binary.LittleEndian, weight)
+		return b
+	}
+	codec.Decode = func(b []byte) (weight T, n int, err error) {
+		n, err = binary.Decode(b, binary.LittleEndian, &weight)
+		return weight, n, err
+	}
+	return codec, codecFixed, size, nil
+}
@@ -0,0 +1,180 @@
+package grafo
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Immutable[int])(nil)
+	_ encoding.BinaryUnmarshaler = (*Immutable[int])(nil)
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	g := NewMutable[int](6)
+	g.AddBoth(0, 1, 5)
+	g.Add(1, 5, -300)
+	g.Add(2, 2, 1<<40)
+	g.Add(5, 0, 0)
+	graphs := []*Immutable[int]{
+		Sort(g),
+		Sort(generateRandom(200, 1000, 1e6, rand.New(rand.NewPCG(0, 1)))),
+		Sort(NewMutable[int](0)),
+	}
+	for _, g := range graphs {
+		data, err := g.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := new(Immutable[int])
+		if err := h.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := String(h), String(g); got != want {
+			t.Errorf("got graph %s, want %s", got, want)
+		}
+		if h.stats != g.stats {
+			t.Errorf("got stats %+v, want %+v", h.stats, g.stats)
+		}
+	}
+}
+
+func TestBinaryWeightTypes(t *testing.T) {
+	t.Run("multigraph float64", func(t *testing.T) {
+		g := Sort(testGraphFromEdges(3, []Edge[float64]{{0, 1, 1.5}, {0, 1, 2.5}, {2, 0, -1}}))
+		testBinaryRoundTrip(t, g)
+	})
+	t.Run("uint8", func(t *testing.T) {
+		g := Sort(testGraphFromEdges(2, []Edge[uint8]{{0, 1, 255}, {1, 1, 0}}))
+		testBinaryRoundTrip(t, g)
+	})
+	t.Run("struct", func(t *testing.T) {
+		type capCost struct {
+			Cap  int32
+			Cost float32
+		}
+		g := Sort(testGraphFromEdges(2, []Edge[capCost]{{0, 1, capCost{3, 0.5}}}))
+		testBinaryRoundTrip(t, g)
+	})
+	t.Run("unweighted", func(t *testing.T) {
+		testBinaryRoundTrip(t, Sort(completeGraph(10)))
+	})
+}
+
+func testBinaryRoundTrip[T any](t *testing.T, g *Immutable[T]) {
+	t.Helper()
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(Immutable[T])
+	if err := h.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := edgeList(h), edgeList(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+}
+
+func edgeList[T any](g Graph[T]) []Edge[T] {
+	var edges []Edge[T]
+	for v := range g.Order() {
+		for w, wt := range g.EdgesFrom(v) {
+			edges = append(edges, Edge[T]{v, w, wt})
+		}
+	}
+	return edges
+}
+
+func testGraphFromEdges[T any](n int, edges []Edge[T]) Graph[T] {
+	g := &Immutable[T]{edges: make([][]neighbor[T], n)}
+	for _, e := range edges {
+		g.edges[e.V] = append(g.edges[e.V], neighbor[T]{e.W, e.Weight})
+	}
+	return g
+}
+
+func TestBinaryCodec(t *testing.T) {
+	g := Sort(testGraphFromEdges(3, []Edge[string]{{0, 1, "a"}, {1, 2, "bc"}, {2, 2, ""}}))
+	if _, err := g.MarshalBinary(); err == nil {
+		t.Fatal("MarshalBinary of string weights succeeded, want error")
+	}
+
+	codec := WeightCodec[string]{
+		Append: func(b []byte, weight string) []byte {
+			b = binary.AppendUvarint(b, uint64(len(weight)))
+			return append(b, weight...)
+		},
+		Decode: func(b []byte) (string, int, error) {
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return "", 0, errors.New("short string")
+			}
+			return string(b[n : n+int(l)]), n + int(l), nil
+		},
+	}
+	data, err := g.MarshalBinaryCodec(codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(Immutable[string])
+	if err := h.UnmarshalBinaryCodec(data, codec); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := edgeList(h), edgeList(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+	if err := new(Immutable[int]).UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary of custom codec data succeeded, want error")
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	g := Sort(generateRandom(20, 50, 100, rand.New(rand.NewPCG(0, 1))))
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := func(i int) []byte {
+		b := append([]byte(nil), data...)
+		b[i] ^= 0xff
+		return b
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", data[:len(data)-1]},
+		{"bad magic", corrupt(0)},
+		{"bad checksum", corrupt(len(data) - 1)},
+		{"corrupted body", corrupt(len(data) / 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := new(Immutable[int]).UnmarshalBinary(tt.data); err == nil {
+				t.Error("UnmarshalBinary succeeded, want error")
+			}
+		})
+	}
+
+	t.Run("wrong weight type", func(t *testing.T) {
+		if err := new(Immutable[float64]).UnmarshalBinary(data); err == nil {
+			t.Error("UnmarshalBinary succeeded, want error")
+		}
+	})
+	t.Run("overflow", func(t *testing.T) {
+		g := Sort(testGraphFromEdges(2, []Edge[int]{{0, 1, 1000}}))
+		data, err := g.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := new(Immutable[int8]).UnmarshalBinary(data); err == nil {
+			t.Error("UnmarshalBinary succeeded, want error")
+		}
+	})
+}
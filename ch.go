@@ -0,0 +1,513 @@
+package grafo
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// CH is a contraction hierarchy of a graph, it answers shortest path
+// queries much faster than ShortestPath after a preprocessing step,
+// which suits many queries on the same large sparse graph, like
+// a road network.
+//
+// The preprocessing contracts the vertices one by one, from the least
+// to the most important. Contracting a vertex v removes it from the
+// graph and adds a shortcut u -> w of cost weight(u, v) + weight(v, w)
+// for each path u -> v -> w that is the only shortest path from u to w.
+// A query searches from both ends using only the edges and shortcuts
+// to vertices contracted later, then unpacks the shortcuts of the path.
+//
+// The methods of CH can be called concurrently.
+type CH[T IntegerOrFloat] struct {
+	// rank[v] is the position of v in the contraction order.
+	rank []int
+	// up[v] has the edges v -> w and down[v] the edges w -> v
+	// with rank[w] > rank[v], sorted by w.
+	up, down chGraph[T]
+
+	pool sync.Pool // of *chQuery[T]
+}
+
+// chEdge is an edge or shortcut of a contraction hierarchy.
+type chEdge[T any] struct {
+	vertex int
+	weight T
+	middle int // the vertex contracted by a shortcut, or -1 for an edge.
+}
+
+type chGraph[T any] [][]chEdge[T]
+
+func (g chGraph[T]) Order() int { return len(g) }
+
+func (g chGraph[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for _, e := range g[v] {
+			if !yield(e.vertex, e.weight) {
+				return
+			}
+		}
+	}
+}
+
+// find returns the edge from v to w.
+func (g chGraph[T]) find(v, w int) (e chEdge[T], ok bool) {
+	i, ok := slices.BinarySearchFunc(g[v], w, func(e chEdge[T], w int) int {
+		return cmp.Compare(e.vertex, w)
+	})
+	if !ok {
+		return e, false
+	}
+	return g[v][i], true
+}
+
+// chSettleLimit bounds the vertices settled by a witness search,
+// when it is reached a shortcut is added even if it isn't needed.
+// The searches that only count the shortcuts to compute the priority
+// of a vertex settle at most chSimulateLimit vertices.
+const (
+	chSettleLimit   = 500
+	chSimulateLimit = 30
+)
+
+// NewCH preprocesses g into a contraction hierarchy.
+// Only edges with non-negative and non-NaN costs are included,
+// as in ShortestPath.
+//
+// The preprocessing time depends on the structure of the graph,
+// it is fast for graphs with low highway dimension, like road networks,
+// and slow for dense graphs, which get many shortcuts.
+func NewCH[T IntegerOrFloat](g Graph[T]) *CH[T] {
+	n := g.Order()
+	c := newContraction(g)
+
+	// Contract the vertices in increasing order of priority,
+	// the priorities are updated lazily when they are popped.
+	prio := make([]int, n)
+	for v := range n {
+		prio[v] = c.priority(v)
+	}
+	Q := newPrioQueue(prio)
+
+	ch := &CH[T]{rank: make([]int, n), up: make(chGraph[T], n), down: make(chGraph[T], n)}
+	for r := 0; Q.Len() > 0; {
+		v := Q.Pop()
+		if p := c.priority(v); Q.Len() > 0 && p > prio[Q.Peek()] {
+			prio[v] = p
+			Q.Push(v)
+			continue
+		}
+		ch.rank[v] = r
+		r++
+		ch.up[v], ch.down[v] = c.contract(v)
+	}
+	return ch
+}
+
+// contraction holds the remaining graph while it is contracted.
+type contraction[T IntegerOrFloat] struct {
+	out, in [][]chEdge[T]
+	// deleted[v] is the number of contracted neighbors of v,
+	// it spreads the contraction uniformly over the graph.
+	deleted []int
+
+	// The state of the witness searches.
+	dist  []T
+	round []int // round[v] == r if v was reached in the round r.
+	r     int
+	Q     *prioQueue[[]T, T]
+}
+
+func newContraction[T IntegerOrFloat](g Graph[T]) *contraction[T] {
+	n := g.Order()
+	c := &contraction[T]{
+		out:     make([][]chEdge[T], n),
+		in:      make([][]chEdge[T], n),
+		deleted: make([]int, n),
+		dist:    make([]T, n),
+		round:   make([]int, n),
+	}
+	c.Q = emptyPrioQueue(c.dist)
+	for v := range n {
+		for w, weight := range g.EdgesFrom(v) {
+			// Loops are never in a shortest path.
+			if v == w || isNaN(weight) || weight < 0 {
+				continue
+			}
+			c.add(v, w, weight, -1)
+		}
+	}
+	return c
+}
+
+// add adds the edge v -> w if there is no shorter one.
+func (c *contraction[T]) add(v, w int, weight T, middle int) {
+	i := slices.IndexFunc(c.out[v], func(e chEdge[T]) bool { return e.vertex == w })
+	if i == -1 {
+		c.out[v] = append(c.out[v], chEdge[T]{w, weight, middle})
+		c.in[w] = append(c.in[w], chEdge[T]{v, weight, middle})
+		return
+	}
+	if weight < c.out[v][i].weight {
+		c.out[v][i] = chEdge[T]{w, weight, middle}
+		j := slices.IndexFunc(c.in[w], func(e chEdge[T]) bool { return e.vertex == v })
+		c.in[w][j] = chEdge[T]{v, weight, middle}
+	}
+}
+
+// remove removes the edge to w from edges.
+func remove[T any](edges []chEdge[T], w int) []chEdge[T] {
+	i := slices.IndexFunc(edges, func(e chEdge[T]) bool { return e.vertex == w })
+	edges[i] = edges[len(edges)-1]
+	return edges[:len(edges)-1]
+}
+
+// shortcuts calls add for each shortcut needed to contract v,
+// the witness searches settle at most settle vertices.
+func (c *contraction[T]) shortcuts(v, settle int, add func(u, w int, weight T)) {
+	for _, a := range c.in[v] {
+		u := a.vertex
+		var limit T
+		for _, b := range c.out[v] {
+			limit = max(limit, addInf(a.weight, b.weight))
+		}
+		c.witnessSearch(u, v, limit, settle)
+		for _, b := range c.out[v] {
+			w := b.vertex
+			if w == u {
+				continue
+			}
+			weight := addInf(a.weight, b.weight)
+			// There is a path from u to w without v as short
+			// as u -> v -> w.
+			if c.round[w] == c.r && c.dist[w] <= weight {
+				continue
+			}
+			add(u, w, weight)
+		}
+	}
+}
+
+// witnessSearch searches the shortest paths from u without v,
+// up to a distance of limit.
+func (c *contraction[T]) witnessSearch(u, v int, limit T, settle int) {
+	c.r++
+	c.Q.heap = c.Q.heap[:0]
+	c.dist[u], c.round[u] = 0, c.r
+	c.Q.Push(u)
+	for settled := 0; c.Q.Len() > 0 && settled < settle; settled++ {
+		x := c.Q.Pop()
+		if c.dist[x] > limit {
+			break
+		}
+		for _, a := range c.out[x] {
+			y := a.vertex
+			if y == v {
+				continue
+			}
+			alt := addInf(c.dist[x], a.weight)
+			switch {
+			case c.round[y] != c.r:
+				c.dist[y], c.round[y] = alt, c.r
+				c.Q.Push(y)
+			case alt < c.dist[y] && c.Q.Contains(y):
+				c.dist[y] = alt
+				c.Q.Fix(y)
+			}
+		}
+	}
+}
+
+// priority returns the priority of contracting v,
+// the vertices with lower priority are contracted first.
+func (c *contraction[T]) priority(v int) int {
+	shortcuts := 0
+	c.shortcuts(v, chSimulateLimit, func(int, int, T) { shortcuts++ })
+	// The edge difference.
+	return shortcuts - len(c.in[v]) - len(c.out[v]) + c.deleted[v]
+}
+
+// contract removes v from the graph, adding the shortcuts needed,
+// and returns the edges from v and to v that remained.
+func (c *contraction[T]) contract(v int) (up, down []chEdge[T]) {
+	c.shortcuts(v, chSettleLimit, func(u, w int, weight T) {
+		c.add(u, w, weight, v)
+	})
+	up, down = c.out[v], c.in[v]
+	for _, e := range up {
+		c.in[e.vertex] = remove(c.in[e.vertex], v)
+		c.deleted[e.vertex]++
+	}
+	for _, e := range down {
+		c.out[e.vertex] = remove(c.out[e.vertex], v)
+		c.deleted[e.vertex]++
+	}
+	c.out[v], c.in[v] = nil, nil
+	byVertex := func(a, b chEdge[T]) int { return cmp.Compare(a.vertex, b.vertex) }
+	slices.SortFunc(up, byVertex)
+	slices.SortFunc(down, byVertex)
+	return slices.Clip(up), slices.Clip(down)
+}
+
+// Order returns the number of vertices of the graph.
+func (c *CH[T]) Order() int { return len(c.rank) }
+
+// ShortestPath computes a shortest path from v to w.
+// The result is the same as ShortestPath on the preprocessed graph:
+// dist is the length of the path, or inf if w cannot be reached.
+// (inf is +inf for floats and the maximum value for integers).
+func (c *CH[T]) ShortestPath(v, w int) (path []int, dist T) {
+	if v == w {
+		return []int{v}, 0
+	}
+	q := c.query()
+	defer c.pool.Put(q)
+	fwd, bwd := q.fwd, q.bwd
+	q.start(fwd, v)
+	q.start(bwd, w)
+
+	// Both searches only go up the hierarchy, the shortest path
+	// goes up from v and down to w meeting at its highest vertex.
+	meet, best := -1, InfFor[T]()
+	for {
+		// A search stops when it can't find a shorter path.
+		fok := fwd.Q.Len() > 0 && (meet == -1 || fwd.min() < best)
+		bok := bwd.Q.Len() > 0 && (meet == -1 || bwd.min() < best)
+		if !fok && !bok {
+			break
+		}
+		s, o := fwd, bwd
+		if !fok || bok && bwd.min() < fwd.min() {
+			s, o = bwd, fwd
+		}
+		u := s.Q.Pop()
+		for x, weight := range s.g.EdgesFrom(u) {
+			if s.parent[x] == -1 {
+				q.touched = append(q.touched, x)
+			}
+			if !s.relax(u, x, weight) || o.parent[x] == -1 {
+				continue
+			}
+			if d := addInf(s.dist[x], o.dist[x]); meet == -1 || d < best {
+				meet, best = x, d
+			}
+		}
+	}
+	if meet == -1 {
+		return []int{}, InfFor[T]()
+	}
+
+	var up []int
+	for x := meet; x != v; x = fwd.parent[x] {
+		up = append(up, x)
+	}
+	path = []int{v}
+	for i, x := len(up)-1, v; i >= 0; i-- {
+		path = c.unpack(path, x, up[i])
+		x = up[i]
+	}
+	for x := meet; x != w; x = bwd.parent[x] {
+		path = c.unpack(path, x, bwd.parent[x])
+	}
+	return path, addInf(fwd.dist[meet], bwd.dist[meet])
+}
+
+// unpack appends to path the vertices after v of the path
+// from v to w represented by the edge or shortcut v -> w.
+func (c *CH[T]) unpack(path []int, v, w int) []int {
+	e, ok := c.up.find(v, w)
+	if !ok {
+		e, _ = c.down.find(w, v)
+	}
+	if e.middle == -1 {
+		return append(path, w)
+	}
+	path = c.unpack(path, v, e.middle)
+	return c.unpack(path, e.middle, w)
+}
+
+// chQuery holds the state of a query, it is reused by the queries.
+type chQuery[T IntegerOrFloat] struct {
+	fwd, bwd *search[T]
+	touched  []int // the vertices reached by the searches.
+}
+
+// query returns a query with no vertices reached.
+func (c *CH[T]) query() *chQuery[T] {
+	q, ok := c.pool.Get().(*chQuery[T])
+	if !ok {
+		n := c.Order()
+		return &chQuery[T]{fwd: emptySearch[T](c.up, n), bwd: emptySearch[T](c.down, n)}
+	}
+	inf := InfFor[T]()
+	for _, v := range q.touched {
+		q.fwd.dist[v], q.fwd.parent[v] = inf, -1
+		q.bwd.dist[v], q.bwd.parent[v] = inf, -1
+	}
+	q.touched = q.touched[:0]
+	q.fwd.Q.heap = q.fwd.Q.heap[:0]
+	q.bwd.Q.heap = q.bwd.Q.heap[:0]
+	return q
+}
+
+// start starts the search s from v.
+func (q *chQuery[T]) start(s *search[T], v int) {
+	s.start(v)
+	q.touched = append(q.touched, v)
+}
+
+// The binary format of a CH is:
+//
+//	magic    "GRCH"
+//	version  byte
+//	codec    byte, as in the binary format of Immutable
+//	size     uvarint, the size of the weights, only for codecFixed
+//	n        uvarint, the number of vertices
+//	m        uvarint, the number of edges and shortcuts
+//	n ranks  uvarint
+//	2n lists the lists of up and then of down, an uvarint degree followed
+//	         by degree triples of the neighbor, as an uvarint delta from
+//	         the previous neighbor, its weight and the middle vertex plus one
+//	checksum CRC-32 (IEEE) of the previous bytes, little endian
+const chBinaryMagic = "GRCH"
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface,
+// the weights are encoded as in Immutable.MarshalBinary.
+func (c *CH[T]) MarshalBinary() ([]byte, error) {
+	codec, id, size, err := defaultCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	n, m := c.Order(), 0
+	for v := range n {
+		m += len(c.up[v]) + len(c.down[v])
+	}
+
+	b := appendBinaryHeader(make([]byte, 0, 16+3*n+4*m), chBinaryMagic, id, size)
+	b = binary.AppendUvarint(b, uint64(n))
+	b = binary.AppendUvarint(b, uint64(m))
+	for _, r := range c.rank {
+		b = binary.AppendUvarint(b, uint64(r))
+	}
+	for _, edges := range slices.Concat(c.up, c.down) {
+		b = binary.AppendUvarint(b, uint64(len(edges)))
+		prev := 0
+		for _, e := range edges {
+			b = binary.AppendUvarint(b, uint64(e.vertex-prev))
+			b = codec.Append(b, e.weight)
+			b = binary.AppendUvarint(b, uint64(e.middle+1))
+			prev = e.vertex
+		}
+	}
+	return appendChecksum(b), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It decodes data written by MarshalBinary into c, replacing
+// its hierarchy.
+func (c *CH[T]) UnmarshalBinary(data []byte) error {
+	codec, id, size, err := defaultCodec[T]()
+	if err != nil {
+		return err
+	}
+	r, err := newBinaryReader(data, chBinaryMagic, id, size)
+	if err != nil {
+		return err
+	}
+	n, err := r.uvarint(len(r.b))
+	if err != nil {
+		return err
+	}
+	m, err := r.uvarint(len(r.b))
+	if err != nil {
+		return err
+	}
+
+	rank := make([]int, n)
+	seen := make([]bool, n)
+	for v := range rank {
+		if rank[v], err = r.uvarint(n - 1); err != nil {
+			return err
+		}
+		if seen[rank[v]] {
+			return fmt.Errorf("grafo: invalid binary data: rank %d repeated", rank[v])
+		}
+		seen[rank[v]] = true
+	}
+
+	lists := make(chGraph[T], 2*n)
+	all := make([]chEdge[T], m)
+	for v := range lists {
+		deg, err := r.uvarint(len(all))
+		if err != nil {
+			return err
+		}
+		edges := all[:deg:deg]
+		all = all[deg:]
+		prev := 0
+		for i := range edges {
+			delta, err := r.uvarint(n - 1 - prev)
+			if err != nil {
+				return err
+			}
+			prev += delta
+			wt, err := readWeight(r, codec)
+			if err != nil {
+				return err
+			}
+			middle, err := r.uvarint(n)
+			if err != nil {
+				return err
+			}
+			edges[i] = chEdge[T]{prev, wt, middle - 1}
+		}
+		lists[v] = edges
+	}
+	if len(all) > 0 {
+		return fmt.Errorf("grafo: invalid binary data: %d edges missing", len(all))
+	}
+	if err := r.end(); err != nil {
+		return err
+	}
+
+	h := &CH[T]{rank: rank, up: lists[:n:n], down: lists[n:]}
+	if err := h.check(); err != nil {
+		return err
+	}
+	c.rank, c.up, c.down = h.rank, h.up, h.down
+	c.pool = sync.Pool{}
+	return nil
+}
+
+// check checks that the edges go up the hierarchy and that the
+// shortcuts unpack, so queries on decoded data don't fail.
+func (c *CH[T]) check() error {
+	for v := range c.Order() {
+		for i, g := range []chGraph[T]{c.up, c.down} {
+			for _, e := range g[v] {
+				a, b := v, e.vertex // The edge a -> b.
+				if i == 1 {
+					a, b = b, a
+				}
+				if c.rank[e.vertex] <= c.rank[v] {
+					return fmt.Errorf("grafo: invalid binary data: edge %d-%d goes down", a, b)
+				}
+				if e.middle == -1 {
+					continue
+				}
+				// The middle vertex is lower than both ends, so
+				// unpacking the shortcuts terminates.
+				m := e.middle
+				_, ok1 := c.down.find(m, a)
+				_, ok2 := c.up.find(m, b)
+				if c.rank[m] >= c.rank[v] || !ok1 || !ok2 {
+					return fmt.Errorf("grafo: invalid binary data: shortcut %d-%d with middle %d", a, b, m)
+				}
+			}
+		}
+	}
+	return nil
+}
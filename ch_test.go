@@ -0,0 +1,163 @@
+package grafo
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCH(t *testing.T) {
+	t.Run("ime", func(t *testing.T) {
+		g := NewMutable[int](6)
+		g.Add(0, 1, 10)
+		g.Add(0, 2, 20)
+		g.Add(1, 3, 70)
+		g.Add(1, 4, 80)
+		g.Add(2, 3, 50)
+		g.Add(2, 4, 60)
+		g.Add(3, 1, 0)
+		g.Add(3, 5, 10)
+		g.Add(4, 5, 10)
+
+		path, dist := NewCH(g).ShortestPath(0, 5)
+		if diff := cmp.Diff(path, []int{0, 2, 3, 5}); diff != "" {
+			t.Errorf("CH.ShortestPath->path %s", diff)
+		}
+		if dist != 80 {
+			t.Errorf("CH.ShortestPath->dist got %d, want 80", dist)
+		}
+	})
+
+	t.Run("skipped edges", func(t *testing.T) {
+		g := NewMutable[float64](4)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, -1)
+		g.Add(0, 2, math.NaN())
+		g.Add(2, 3, 1)
+		g.Add(3, 3, 1)
+
+		ch := NewCH(g)
+		path, dist := ch.ShortestPath(0, 3)
+		if diff := cmp.Diff(path, []int{}); diff != "" {
+			t.Errorf("CH.ShortestPath->path %s", diff)
+		}
+		if dist != InfFor[float64]() {
+			t.Errorf("CH.ShortestPath->dist got %v, want inf", dist)
+		}
+		path, dist = ch.ShortestPath(3, 3)
+		if diff := cmp.Diff(path, []int{3}); diff != "" {
+			t.Errorf("CH.ShortestPath->path %s", diff)
+		}
+		if dist != 0 {
+			t.Errorf("CH.ShortestPath->dist got %v, want 0", dist)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for i := range 40 {
+			var g *Mutable[int]
+			if i%2 == 0 {
+				n := 2 + rnd.IntN(60)
+				g = generateRandom(n, rnd.IntN(min(3*n, n*(n-1))+1), 20, rnd)
+			} else {
+				g, _ = gridGraph(2+rnd.IntN(10), 2+rnd.IntN(10), 10, 0, rnd)
+			}
+			ch := NewCH(g)
+			n := g.Order()
+			for range 50 {
+				v, w := rnd.IntN(n), rnd.IntN(n)
+				testCHQuery(t, g, ch, v, w)
+			}
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		g, _ := gridGraph(20, 20, 10, 0, rand.New(rand.NewPCG(1, 2)))
+		ch := NewCH(g)
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rnd := rand.New(rand.NewPCG(uint64(i), 1))
+				for range 50 {
+					testCHQuery(t, g, ch, rnd.IntN(400), rnd.IntN(400))
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func testCHQuery(t *testing.T, g *Mutable[int], ch *CH[int], v, w int) {
+	t.Helper()
+	_, want := ShortestPath(g, v, w)
+	path, dist := ch.ShortestPath(v, w)
+	if dist != want {
+		t.Errorf("CH.ShortestPath(%d, %d) on %s got dist %d, want %d", v, w, String(g), dist, want)
+		return
+	}
+	if dist == InfFor[int]() {
+		if len(path) != 0 {
+			t.Errorf("CH.ShortestPath(%d, %d) on %s got path %v, want []", v, w, String(g), path)
+		}
+		return
+	}
+	if path[0] != v || path[len(path)-1] != w || pathLength(g, path) != dist {
+		t.Errorf("CH.ShortestPath(%d, %d) on %s got path %v, want a path of length %d", v, w, String(g), path, dist)
+	}
+}
+
+func TestCHBinary(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	g, _ := gridGraph(15, 15, 10, 0, rnd)
+	data, err := NewCH(g).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := new(CH[int])
+	if err := ch.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		testCHQuery(t, g, ch, rnd.IntN(225), rnd.IntN(225))
+	}
+
+	if err := new(CH[float64]).UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary with the wrong weight type succeeded, want error")
+	}
+	if err := new(Immutable[int]).UnmarshalBinary(data); err == nil {
+		t.Error("Immutable.UnmarshalBinary of a CH succeeded, want error")
+	}
+	b := append([]byte(nil), data...)
+	b[len(b)/2] ^= 0xff
+	if err := new(CH[int]).UnmarshalBinary(b); err == nil {
+		t.Error("UnmarshalBinary of corrupted data succeeded, want error")
+	}
+
+	// A shortcut whose middle vertex is higher than its ends
+	// would loop forever when unpacked.
+	bad := NewCH(g)
+	top := 0
+	for v, r := range bad.rank {
+		if r == len(bad.rank)-1 {
+			top = v
+		}
+	}
+	for v := range bad.up {
+		if len(bad.up[v]) > 0 && bad.up[v][0].vertex != top {
+			bad.up[v][0].middle = top
+			break
+		}
+	}
+	if data, err = bad.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+	if err := new(CH[int]).UnmarshalBinary(data); err == nil {
+		t.Error("UnmarshalBinary of an invalid shortcut succeeded, want error")
+	}
+}
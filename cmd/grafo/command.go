@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+// algorithmArgs holds the number of vertices taken by each algorithm.
+var algorithmArgs = map[string]int{
+	"shortestpath": 2,
+	"bellmanford":  1,
+	"mst":          0,
+	"maxflow":      2,
+	"topsort":      0,
+	"strong":       0,
+	"bipartition":  0,
+}
+
+func command[T grafo.IntegerOrFloat](cmd, algo string, vertices []string, opts options, in io.Reader, stdout io.Writer, wts weights[T]) error {
+	dec, err := newDecoder(opts.from, in, wts)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "convert" {
+		if opts.to == "" {
+			opts.to = formatOf(opts.out)
+			if opts.to == "" {
+				return fmt.Errorf("convert: can't infer the output format, use -to")
+			}
+		}
+		return convert(dec, opts, stdout, wts)
+	}
+
+	g, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+	h := grafo.Sort(g)
+
+	var res result
+	if cmd == "stats" {
+		s := h.Stats()
+		res = statsResult{Order: h.Order(), Size: s.Size, Multi: s.Multi, Loops: s.Loops, Isolated: s.Isolated}
+	} else {
+		vs := make([]int, len(vertices))
+		for i, s := range vertices {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("run %s: %w", algo, err)
+			}
+			if v < 0 || v >= h.Order() {
+				return fmt.Errorf("run %s: vertex %d out of range [0, %d)", algo, v, h.Order())
+			}
+			vs[i] = v
+		}
+		if res, err = runAlgorithm(algo, h, vs); err != nil {
+			return err
+		}
+	}
+
+	if opts.json {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	res.writeText(stdout)
+	return nil
+}
+
+// convert decodes a graph and encodes it in the format opts.to to
+// opts.out, or stdout, via encoding.Transform. The output file is
+// created on the first write, so a decoding error doesn't truncate it.
+func convert[T grafo.IntegerOrFloat](dec encoding.Decoder[T], opts options, stdout io.Writer, wts weights[T]) (err error) {
+	var out io.Writer = stdout
+	if opts.out != "" {
+		f := &lazyFile{name: opts.out}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = f
+	}
+	names := func() []string {
+		if n, ok := dec.(namer); ok {
+			return n.Names()
+		}
+		return nil
+	}
+	enc, err := newEncoder(opts.to, out, wts, names)
+	if err != nil {
+		return err
+	}
+	return encoding.Transform(enc, dec)
+}
+
+// lazyFile is a writer that creates the file name on the first write.
+type lazyFile struct {
+	name string
+	f    *os.File
+}
+
+func (l *lazyFile) Write(p []byte) (int, error) {
+	if l.f == nil {
+		f, err := os.Create(l.name)
+		if err != nil {
+			return 0, err
+		}
+		l.f = f
+	}
+	return l.f.Write(p)
+}
+
+// Close closes the file if it was created.
+func (l *lazyFile) Close() error {
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+func runAlgorithm[T grafo.IntegerOrFloat](algo string, g *grafo.Immutable[T], vs []int) (result, error) {
+	switch algo {
+	case "shortestpath":
+		path, dist := grafo.ShortestPath(g, vs[0], vs[1])
+		res := pathResult[T]{Path: path}
+		if len(path) > 0 {
+			res.Dist = &dist
+		}
+		return res, nil
+	case "bellmanford":
+		parent, dist, ok := grafo.BellmanFord(g, vs[0])
+		res := bellmanFordResult[T]{OK: ok}
+		if ok {
+			res.Parent = parent
+			res.Dist = finite(dist)
+		}
+		return res, nil
+	case "mst":
+		return mstResult{Parent: grafo.MST(g)}, nil
+	case "maxflow":
+		flow, fg := grafo.MaxFlow(g, vs[0], vs[1])
+		res := maxFlowResult[T]{Edges: []flowEdge[T]{}}
+		if flow != grafo.InfFor[T]() {
+			res.Flow = &flow
+		}
+		for v := range fg.Order() {
+			for w, f := range fg.EdgesFrom(v) {
+				res.Edges = append(res.Edges, flowEdge[T]{v, w, f})
+			}
+		}
+		return res, nil
+	case "topsort":
+		order, ok := grafo.TopSort(g)
+		return orderResult{Order: order, OK: ok}, nil
+	case "strong":
+		return componentsResult{Components: grafo.StrongComponents(g)}, nil
+	case "bipartition":
+		part, ok := grafo.Bipartition(g)
+		return bipartitionResult{Part: part, OK: ok}, nil
+	}
+	return nil, fmt.Errorf("run: unknown algorithm %q", algo)
+}
+
+// finite returns dist with the infinite distances as nil,
+// JSON can't represent infinity.
+func finite[T grafo.IntegerOrFloat](dist []T) []*T {
+	inf := grafo.InfFor[T]()
+	res := make([]*T, len(dist))
+	for i := range dist {
+		if dist[i] != inf {
+			res[i] = &dist[i]
+		}
+	}
+	return res
+}
+
+// result is the result of a command, it is printed as JSON
+// or as text by writeText.
+type result interface {
+	writeText(w io.Writer)
+}
+
+type statsResult struct {
+	Order    int `json:"order"`
+	Size     int `json:"size"`
+	Multi    int `json:"multi"`
+	Loops    int `json:"loops"`
+	Isolated int `json:"isolated"`
+}
+
+func (r statsResult) writeText(w io.Writer) {
+	fmt.Fprintf(w, "order: %d\nsize: %d\nmulti: %d\nloops: %d\nisolated: %d\n",
+		r.Order, r.Size, r.Multi, r.Loops, r.Isolated)
+}
+
+type pathResult[T any] struct {
+	Path []int `json:"path"`
+	Dist *T    `json:"dist"` // nil if there is no path.
+}
+
+func (r pathResult[T]) writeText(w io.Writer) {
+	if r.Dist == nil {
+		fmt.Fprintln(w, "no path")
+		return
+	}
+	fmt.Fprintf(w, "path: %s\ndist: %v\n", join(r.Path), *r.Dist)
+}
+
+type bellmanFordResult[T any] struct {
+	Parent []int `json:"parent"`
+	Dist   []*T  `json:"dist"` // nil for unreachable vertices.
+	OK     bool  `json:"ok"`   // false if there is a negative cycle.
+}
+
+func (r bellmanFordResult[T]) writeText(w io.Writer) {
+	if !r.OK {
+		fmt.Fprintln(w, "negative cycle")
+		return
+	}
+	fmt.Fprintln(w, "vertex parent dist")
+	for v, p := range r.Parent {
+		dist := "inf"
+		if r.Dist[v] != nil {
+			dist = fmt.Sprint(*r.Dist[v])
+		}
+		fmt.Fprintf(w, "%d %d %s\n", v, p, dist)
+	}
+}
+
+type mstResult struct {
+	Parent []int `json:"parent"`
+}
+
+func (r mstResult) writeText(w io.Writer) {
+	fmt.Fprintln(w, "vertex parent")
+	for v, p := range r.Parent {
+		fmt.Fprintf(w, "%d %d\n", v, p)
+	}
+}
+
+type flowEdge[T any] struct {
+	V    int `json:"v"`
+	W    int `json:"w"`
+	Flow T   `json:"flow"`
+}
+
+type maxFlowResult[T any] struct {
+	Flow  *T            `json:"flow"` // nil for an infinite flow.
+	Edges []flowEdge[T] `json:"edges"`
+}
+
+func (r maxFlowResult[T]) writeText(w io.Writer) {
+	flow := "inf"
+	if r.Flow != nil {
+		flow = fmt.Sprint(*r.Flow)
+	}
+	fmt.Fprintf(w, "flow: %s\n", flow)
+	for _, e := range r.Edges {
+		fmt.Fprintf(w, "%d %d %v\n", e.V, e.W, e.Flow)
+	}
+}
+
+type orderResult struct {
+	Order []int `json:"order"`
+	OK    bool  `json:"ok"` // false if the graph has a cycle.
+}
+
+func (r orderResult) writeText(w io.Writer) {
+	if !r.OK {
+		fmt.Fprintln(w, "cycle")
+		return
+	}
+	fmt.Fprintf(w, "order: %s\n", join(r.Order))
+}
+
+type componentsResult struct {
+	Components [][]int `json:"components"`
+}
+
+func (r componentsResult) writeText(w io.Writer) {
+	for _, c := range r.Components {
+		fmt.Fprintln(w, join(c))
+	}
+}
+
+type bipartitionResult struct {
+	Part []int `json:"part"`
+	OK   bool  `json:"ok"` // false if the graph is not bipartite.
+}
+
+func (r bipartitionResult) writeText(w io.Writer) {
+	if !r.OK {
+		fmt.Fprintln(w, "not bipartite")
+		return
+	}
+	fmt.Fprintf(w, "part: %s\n", join(r.Part))
+}
+
+func join(vs []int) string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/encoding/dot"
+	"github.com/rschio/grafo/encoding/edgelist"
+	"github.com/rschio/grafo/encoding/gr"
+	"github.com/rschio/grafo/encoding/graphml"
+	"github.com/rschio/grafo/encoding/jsongraph"
+	"github.com/rschio/grafo/encoding/metis"
+	"github.com/rschio/grafo/encoding/mtx"
+	"github.com/rschio/grafo/encoding/simple"
+)
+
+// weights parses and formats the weights of the edges.
+type weights[T any] struct {
+	parse  func(string) (T, error)
+	format func(T) string
+}
+
+func parseInt(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) }
+func formatInt(x int64) string         { return strconv.FormatInt(x, 10) }
+
+func parseFloat(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+func formatFloat(x float64) string         { return strconv.FormatFloat(x, 'g', -1, 64) }
+
+var extensions = map[string]string{
+	".txt":     "simple",
+	".gr":      "gr",
+	".dot":     "dot",
+	".gv":      "dot",
+	".graphml": "graphml",
+	".json":    "jgf",
+	".mtx":     "mtx",
+	".graph":   "metis",
+	".csv":     "csv",
+	".tsv":     "tsv",
+}
+
+// formatOf returns the format of the file name, or "" if it is unknown.
+func formatOf(name string) string {
+	return extensions[strings.ToLower(filepath.Ext(name))]
+}
+
+func newDecoder[T any](format string, r io.Reader, wts weights[T]) (encoding.Decoder[T], error) {
+	switch format {
+	case "simple":
+		return simple.NewDecoder(r, wts.parse), nil
+	case "gr":
+		return gr.NewDecoder(r, wts.parse), nil
+	case "dot":
+		return dot.NewDecoder(r, wts.parse), nil
+	case "graphml":
+		return graphml.NewDecoder(r, wts.parse), nil
+	case "jgf", "nodelink":
+		return jsongraph.NewDecoder[T](r), nil
+	case "mtx":
+		return mtx.NewDecoder(r, wts.parse), nil
+	case "metis":
+		return metis.NewDecoder(r, wts.parse), nil
+	case "csv":
+		return edgelist.NewDecoder(r, wts.parse), nil
+	case "tsv":
+		dec := edgelist.NewDecoder(r, wts.parse)
+		dec.SetDelimiter('\t')
+		return dec, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// namer is implemented by the decoders that keep the names of the
+// vertices, e.g. the node IDs of DOT or GraphML.
+type namer interface {
+	Names() []string
+}
+
+// encoderFunc adapts a function to the encoding.Encoder interface.
+type encoderFunc[T any] func(grafo.Graph[T]) error
+
+func (f encoderFunc[T]) Encode(g grafo.Graph[T]) error { return f(g) }
+
+// newEncoder returns an encoder of the format that writes to w.
+// The formats with labels write names()[v] as the label of v, names
+// is called only when the graph is encoded and may return nil.
+func newEncoder[T any](format string, w io.Writer, wts weights[T], names func() []string) (encoding.Encoder[T], error) {
+	// label returns the name of v, or "" if it has none.
+	label := func(v int) string {
+		if ns := names(); v < len(ns) {
+			return ns[v]
+		}
+		return ""
+	}
+	data := func(v int) map[string]any {
+		if l := label(v); l != "" {
+			return map[string]any{"label": l}
+		}
+		return nil
+	}
+
+	switch format {
+	case "simple":
+		return simple.NewEncoder(w, wts.format), nil
+	case "gr":
+		return gr.NewEncoder(w, wts.format), nil
+	case "dot":
+		enc := dot.NewEncoder(w, wts.format)
+		enc.SetVertexAttrs(func(v int) dot.Attrs {
+			if l := label(v); l != "" {
+				return dot.Attrs{"label": l}
+			}
+			return nil
+		})
+		return enc, nil
+	case "graphml":
+		enc := graphml.NewEncoder(w, wts.format)
+		enc.SetVertexData(data)
+		return enc, nil
+	case "jgf", "nodelink":
+		f := jsongraph.JGF
+		if format == "nodelink" {
+			f = jsongraph.NodeLink
+		}
+		enc := jsongraph.NewEncoder[T](w, f)
+		enc.SetVertexData(data)
+		return enc, nil
+	case "mtx":
+		return mtx.NewEncoder(w, wts.format), nil
+	case "metis":
+		return metis.NewEncoder(w, wts.format), nil
+	case "csv", "tsv":
+		enc := edgelist.NewEncoder(w, wts.format)
+		if format == "tsv" {
+			enc.SetDelimiter('\t')
+		}
+		return encoderFunc[T](func(g grafo.Graph[T]) error {
+			enc.SetNames(names())
+			return enc.Encode(g)
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
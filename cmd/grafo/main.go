@@ -0,0 +1,139 @@
+// Grafo converts, inspects and runs algorithms on graphs.
+//
+// Usage:
+//
+//	grafo convert [-from format] [-to format] [-o file] [file]
+//	grafo stats [-from format] [-json] [file]
+//	grafo run [-from format] [-json] algorithm [vertices...] [file]
+//
+// The graph is read from file, or from the standard input if there
+// is no file. The formats are simple, gr, dot, graphml, jgf,
+// nodelink, mtx, metis, csv and tsv, by default they are inferred
+// from the file extensions.
+//
+// The algorithms are:
+//
+//	shortestpath v w   shortest path from v to w
+//	bellmanford v      shortest paths from v, allowing negative weights
+//	mst                minimum spanning forest
+//	maxflow s t        maximum flow from s to t
+//	topsort            topological order
+//	strong             strongly connected components
+//	bipartition        bipartition of the vertices
+//
+// Vertices are 0 indexed. The weights are integers, the flag -float
+// reads them as floating-point numbers.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage:
+	grafo convert [-from format] [-to format] [-o file] [file]
+	grafo stats [-from format] [-json] [file]
+	grafo run [-from format] [-json] algorithm [vertices...] [file]
+
+Run 'grafo command -h' for the flags of a command.
+`
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grafo:", err)
+		os.Exit(1)
+	}
+}
+
+// options holds the flags shared by the commands.
+type options struct {
+	from  string
+	float bool
+	json  bool
+
+	// Only for convert.
+	to  string
+	out string
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+	cmd, args := args[0], args[1:]
+
+	var opts options
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.from, "from", "", "input `format`, by default inferred from the file extension")
+	fs.BoolVar(&opts.float, "float", false, "read the weights as floating-point numbers")
+	switch cmd {
+	case "convert":
+		fs.StringVar(&opts.to, "to", "", "output `format`, by default inferred from the -o extension")
+		fs.StringVar(&opts.out, "o", "", "output `file`, by default the standard output")
+	case "stats", "run":
+		fs.BoolVar(&opts.json, "json", false, "print the result as JSON")
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+
+	var algo string
+	var vertices []string
+	if cmd == "run" {
+		if len(args) == 0 {
+			return errors.New("run: missing algorithm")
+		}
+		algo, args = args[0], args[1:]
+		n, ok := algorithmArgs[algo]
+		if !ok {
+			return fmt.Errorf("run: unknown algorithm %q", algo)
+		}
+		if len(args) < n {
+			return fmt.Errorf("run %s: got %d vertices, want %d", algo, len(args), n)
+		}
+		vertices, args = args[:n], args[n:]
+	}
+
+	var in io.Reader = stdin
+	var inName string
+	switch len(args) {
+	case 0:
+	case 1:
+		inName = args[0]
+		f, err := os.Open(inName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return fmt.Errorf("%s: too many arguments", cmd)
+	}
+	if opts.from == "" {
+		opts.from = formatOf(inName)
+		if opts.from == "" {
+			return fmt.Errorf("%s: can't infer the input format, use -from", cmd)
+		}
+	}
+
+	if opts.float {
+		return command[float64](cmd, algo, vertices, opts, in, stdout, weights[float64]{parseFloat, formatFloat})
+	}
+	return command[int64](cmd, algo, vertices, opts, in, stdout, weights[int64]{parseInt, formatInt})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sample = `4
+0 1 3
+1 2 4
+0 2 10
+2 3 1
+`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"stats", []string{"stats", "-from", "simple"}, "order: 4\nsize: 4\nmulti: 0\nloops: 0\nisolated: 1\n"},
+		{"stats json", []string{"stats", "-from", "simple", "-json"}, `"isolated": 1`},
+		{"shortest path", []string{"run", "-from", "simple", "shortestpath", "0", "3"}, "path: 0 1 2 3\ndist: 8\n"},
+		{"no path", []string{"run", "-from", "simple", "-json", "shortestpath", "3", "0"}, `"dist": null`},
+		{"bellman-ford float", []string{"run", "-from", "simple", "-float", "bellmanford", "1"}, "0 -1 inf\n1 -1 0\n2 1 4\n3 2 5\n"},
+		{"mst", []string{"run", "-from", "simple", "mst"}, "vertex parent\n0 -1\n1 0\n2 1\n3 2\n"},
+		{"max flow", []string{"run", "-from", "simple", "maxflow", "0", "3"}, "flow: 1\n"},
+		{"float max flow", []string{"run", "-from", "simple", "-float", "maxflow", "0", "3"}, "flow: 1\n"},
+		{"infinite max flow", []string{"run", "-from", "simple", "-json", "maxflow", "0", "0"}, `"flow": null`},
+		{"topological sort", []string{"run", "-from", "simple", "topsort"}, "order: 0 1 2 3\n"},
+		{"strong components", []string{"run", "-from", "simple", "-json", "strong"}, `"components": [`},
+		{"bipartition", []string{"run", "-from", "simple", "bipartition"}, "not bipartite\n"},
+		{"convert", []string{"convert", "-from", "simple", "-to", "gr"}, "p sp 4 4\na 1 2 3\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, strings.NewReader(sample), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("run(%q) error: %v\n%s", tt.args, err, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("run(%q) output:\n%s\nwant to contain:\n%s", tt.args, stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertNames(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		in   string
+		want string
+	}{
+		{"csv to csv", []string{"convert", "-from", "csv", "-to", "csv"}, "a,b,3\nb,c,4\n", "a,b,3\nb,c,4\n"},
+		{"csv to dot", []string{"convert", "-from", "csv", "-to", "dot"}, "a,b,3\n", "0 [label=a]\n\t1 [label=b]\n"},
+		{"dot to jgf", []string{"convert", "-from", "dot", "-to", "jgf"}, "digraph { x -> y }", `"0":{"label":"x"}`},
+		{"dot to gr", []string{"convert", "-from", "dot", "-to", "gr"}, "digraph {\n\t0\n\t1\n\t2\n\t1 -> 0 [weight=5]\n}\n", "a 2 1 5\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.in), &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("run(%q) error: %v\n%s", tt.args, err, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("run(%q) output:\n%s\nwant to contain:\n%s", tt.args, stdout.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "g.txt")
+	out := filepath.Join(dir, "g.graphml")
+	if err := os.WriteFile(in, []byte(sample), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"convert", "-o", out, in}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("convert: %v\n%s", err, stderr.String())
+	}
+	if err := run([]string{"stats", out}, nil, &stdout, &stderr); err != nil {
+		t.Fatalf("stats: %v\n%s", err, stderr.String())
+	}
+	if want := "order: 4\nsize: 4\n"; !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("got stats:\n%s\nwant prefix:\n%s", stdout.String(), want)
+	}
+
+	// A decoding error leaves the output file as it was.
+	before, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"convert", "-from", "gr", "-o", out, in}, nil, &stdout, &stderr); err == nil {
+		t.Fatal("convert of a bad input succeeded, want error")
+	}
+	if after, err := os.ReadFile(out); err != nil || !bytes.Equal(after, before) {
+		t.Errorf("convert of a bad input changed the output file: %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no command", nil},
+		{"unknown command", []string{"draw"}},
+		{"unknown algorithm", []string{"run", "-from", "simple", "dijkstra"}},
+		{"missing vertices", []string{"run", "-from", "simple", "shortestpath", "0"}},
+		{"vertex out of range", []string{"run", "-from", "simple", "shortestpath", "0", "4"}},
+		{"unknown input format", []string{"stats"}},
+		{"unknown output format", []string{"convert", "-from", "simple"}},
+		{"bad input", []string{"stats", "-from", "gr"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := run(tt.args, strings.NewReader(sample), &stdout, &stderr); err == nil {
+				t.Errorf("run(%q) succeeded, want error", tt.args)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package grafo
+
+import (
+	"container/heap"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// DeltaStepping computes the shortest paths from v to all other vertices,
+// like ShortestPaths, relaxing the edges in parallel.
+// The results have the same meaning as in ShortestPaths and dist is
+// the same, parent may be a different tree of shortest paths if there
+// are paths of the same length.
+//
+// The vertices are put in buckets of width delta by their distance,
+// the buckets are processed in order and the edges from the vertices
+// in a bucket are relaxed by workers goroutines, or GOMAXPROCS if
+// workers isn't positive. A small delta does less extra work, a large
+// delta relaxes more edges in parallel; the mean weight divided by the
+// mean degree is a good start. DeltaStepping panics if delta isn't positive.
+//
+// The EdgesFrom method of g is called concurrently.
+func DeltaStepping[T IntegerOrFloat](g Graph[T], v int, delta T, workers int) (parent []int, dist []T) {
+	if !(delta > 0) {
+		panic("grafo: DeltaStepping delta must be positive")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := g.Order()
+	d := &deltaStepping[T]{
+		g:       g,
+		delta:   delta,
+		float:   isFloat[T](),
+		workers: workers,
+		dist:    make([]T, n),
+		parent:  make([]int, n),
+		changed: make([]bool, n),
+		buckets: make(map[T][]int),
+	}
+	inf := InfFor[T]()
+	for i := range n {
+		d.dist[i], d.parent[i] = inf, -1
+	}
+	d.dist[v], d.parent[v] = 0, v
+	d.insert([]int{v})
+
+	inBucket := make([]bool, n)
+	var settled []int
+	for d.keys.Len() > 0 {
+		k := heap.Pop(&d.keys).(T)
+		// Relax the light edges until the bucket is empty,
+		// they may put vertices back in the bucket.
+		settled = settled[:0]
+		for len(d.buckets[k]) > 0 {
+			b := d.buckets[k]
+			delete(d.buckets, k)
+			frontier := b[:0]
+			for _, u := range b {
+				// Skip the vertices that moved to another bucket.
+				if d.bucket(d.dist[u]) == k && !inBucket[u] {
+					inBucket[u] = true
+					frontier = append(frontier, u)
+				}
+			}
+			for _, u := range frontier {
+				inBucket[u] = false
+			}
+			settled = append(settled, frontier...)
+			d.insert(d.relax(frontier, true))
+		}
+		// Each vertex is relaxed once.
+		frontier := settled[:0]
+		for _, u := range settled {
+			if !inBucket[u] {
+				inBucket[u] = true
+				frontier = append(frontier, u)
+			}
+		}
+		for _, u := range frontier {
+			inBucket[u] = false
+		}
+		d.insert(d.relax(frontier, false))
+	}
+
+	d.parent[v] = -1
+	return d.parent, d.dist
+}
+
+// deltaStepping holds the state of DeltaStepping.
+type deltaStepping[T IntegerOrFloat] struct {
+	g       Graph[T]
+	delta   T
+	float   bool
+	workers int
+
+	dist    []T
+	parent  []int
+	changed []bool // changed[v] is true if dist[v] changed in a relax.
+
+	// buckets[k] has the vertices at distances [k⋅delta, (k+1)⋅delta),
+	// and keys the indices k of the buckets, some may be empty.
+	buckets map[T][]int
+	keys    bucketKeys[T]
+}
+
+// deltaRequest is a request to relax the edge u -> w to a distance dist.
+type deltaRequest[T any] struct {
+	u, w int
+	dist T
+}
+
+// deltaMinChunk is the minimum number of vertices relaxed by a worker.
+const deltaMinChunk = 64
+
+// relax relaxes the light or the heavy edges, with weight greater than
+// delta, from the frontier and returns the vertices whose distance changed.
+func (d *deltaStepping[T]) relax(frontier []int, light bool) []int {
+	k := min(d.workers, (len(frontier)+deltaMinChunk-1)/deltaMinChunk)
+	if k <= 1 {
+		k = 1
+	}
+	// Each worker makes the requests of a part of the frontier,
+	// reqs[i][j] has the requests of the worker i to the vertices
+	// owned by the worker j. Then each worker applies the requests
+	// to its vertices, w is owned by the worker w % k.
+	reqs := make([][][]deltaRequest[T], k)
+	updated := make([][]int, k)
+	parallel(k, func(i int) {
+		reqs[i] = make([][]deltaRequest[T], k)
+		for _, u := range frontier[i*len(frontier)/k : (i+1)*len(frontier)/k] {
+			for w, weight := range d.g.EdgesFrom(u) {
+				// Skip NaN and negative edges.
+				if isNaN(weight) || weight < 0 || (weight <= d.delta) != light {
+					continue
+				}
+				alt := addInf(d.dist[u], weight)
+				if d.parent[w] != -1 && alt >= d.dist[w] {
+					continue
+				}
+				reqs[i][w%k] = append(reqs[i][w%k], deltaRequest[T]{u, w, alt})
+			}
+		}
+	})
+	parallel(k, func(j int) {
+		for i := range k {
+			for _, r := range reqs[i][j] {
+				if d.parent[r.w] != -1 && r.dist >= d.dist[r.w] {
+					continue
+				}
+				d.dist[r.w], d.parent[r.w] = r.dist, r.u
+				if !d.changed[r.w] {
+					d.changed[r.w] = true
+					updated[j] = append(updated[j], r.w)
+				}
+			}
+		}
+		for _, w := range updated[j] {
+			d.changed[w] = false
+		}
+	})
+
+	var all []int
+	for _, u := range updated {
+		all = append(all, u...)
+	}
+	return all
+}
+
+// isFloat tells whether T is a floating-point type.
+func isFloat[T IntegerOrFloat]() bool {
+	var x T = 1
+	return x/2 != 0
+}
+
+// parallel calls f(0), ..., f(k-1) in k goroutines
+// and waits for them.
+func parallel(k int, f func(i int)) {
+	if k == 1 {
+		f(0)
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range k {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// insert puts the vertices in the buckets of their distances.
+func (d *deltaStepping[T]) insert(vertices []int) {
+	for _, v := range vertices {
+		k := d.bucket(d.dist[v])
+		if len(d.buckets[k]) == 0 {
+			heap.Push(&d.keys, k)
+		}
+		d.buckets[k] = append(d.buckets[k], v)
+	}
+}
+
+// bucket returns the index of the bucket of dist.
+func (d *deltaStepping[T]) bucket(dist T) T {
+	k := dist / d.delta
+	// Round down the floats, the integer division already does.
+	if d.float {
+		return T(math.Floor(float64(k)))
+	}
+	return k
+}
+
+// bucketKeys is a min-heap of the indices of the buckets.
+type bucketKeys[T IntegerOrFloat] []T
+
+func (h bucketKeys[T]) Len() int           { return len(h) }
+func (h bucketKeys[T]) Less(i, j int) bool { return h[i] < h[j] }
+func (h bucketKeys[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *bucketKeys[T]) Push(x any)        { *h = append(*h, x.(T)) }
+func (h *bucketKeys[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
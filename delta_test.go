@@ -0,0 +1,110 @@
+package grafo
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeltaStepping(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(3000)
+			g := generateRandomWithRand(n, rnd.IntN(5*n), func() int { return rnd.IntN(100) }, rnd)
+			v := rnd.IntN(n)
+			delta, workers := 1+rnd.IntN(200), rnd.IntN(8)
+			_, want := ShortestPaths(g, v)
+			parent, dist := DeltaStepping(g, v, delta, workers)
+			if diff := cmp.Diff(dist, want); diff != "" {
+				t.Fatalf("DeltaStepping(%d, %d, %d) dist %s", v, delta, workers, diff)
+			}
+			testParent(t, g, v, parent, dist)
+		}
+	})
+
+	t.Run("inf", func(t *testing.T) {
+		inf := InfFor[int]()
+		g := NewMutable[int](5)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, inf)
+		g.Add(2, 3, 1)
+		g.Add(4, 0, 1)
+		parent, dist := DeltaStepping(g, 0, 3, 2)
+		if diff := cmp.Diff(dist, []int{0, 1, inf, inf, inf}); diff != "" {
+			t.Errorf("DeltaStepping->dist %s", diff)
+		}
+		if diff := cmp.Diff(parent, []int{-1, 0, 1, 2, -1}); diff != "" {
+			t.Errorf("DeltaStepping->parent %s", diff)
+		}
+	})
+
+	t.Run("float", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(500)
+			weight := func() float64 {
+				switch rnd.IntN(20) {
+				case 0:
+					return math.NaN()
+				case 1:
+					return -1
+				}
+				return rnd.Float64()
+			}
+			g := generateRandomWithRand(n, rnd.IntN(5*n), weight, rnd)
+			v := rnd.IntN(n)
+			delta := 0.01 + rnd.Float64()
+			_, want := ShortestPaths(g, v)
+			parent, dist := DeltaStepping(g, v, delta, 4)
+			if diff := cmp.Diff(dist, want); diff != "" {
+				t.Fatalf("DeltaStepping(%d, %v) dist %s", v, delta, diff)
+			}
+			testParent(t, g, v, parent, dist)
+		}
+	})
+
+	t.Run("delta", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("DeltaStepping with delta 0 didn't panic")
+			}
+		}()
+		DeltaStepping(NewMutable[int](1), 0, 0, 1)
+	})
+}
+
+// testParent checks that parent is a tree of shortest paths from v.
+func testParent[T IntegerOrFloat](t *testing.T, g Graph[T], v int, parent []int, dist []T) {
+	t.Helper()
+	for w, p := range parent {
+		if p == -1 {
+			if w != v && dist[w] != InfFor[T]() {
+				t.Fatalf("parent[%d] = -1, but dist[%d] = %v", w, w, dist[w])
+			}
+			continue
+		}
+		ok := false
+		for x, weight := range g.EdgesFrom(p) {
+			if x == w && weight >= 0 && addInf(dist[p], weight) == dist[w] {
+				ok = true
+			}
+		}
+		if !ok {
+			t.Fatalf("parent[%d] = %d, but there is no edge of cost %v", w, p, dist[w]-dist[p])
+		}
+	}
+}
+
+func BenchmarkDeltaStepping(b *testing.B) {
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				_, _ = DeltaStepping(dimacsG, 0, 1000, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package dot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Decoder reads graphs in the DOT language.
+//
+// The decoder understands the whole DOT grammar: strict graphs,
+// graphs and digraphs, node, edge and attribute statements,
+// subgraphs, edge chains like a -> b -> c, quoted and HTML IDs and
+// comments. Node IDs are mapped to vertices 0, 1, ..., n-1 in the
+// order they first appear, see Names.
+//
+// Attributes other than the weight attribute, ports and compass
+// points are parsed and ignored.
+type Decoder[T any] struct {
+	r           io.Reader
+	lex         *lexer
+	parseWeight func(string) (T, error)
+	weightAttr  string
+	names       []string
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weight of the edges.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+		weightAttr:  "weight",
+	}
+}
+
+// SetWeightAttr sets the edge attribute that holds the weight,
+// the default is "weight". Edges without the attribute have
+// the zero value of T as weight.
+func (d *Decoder[T]) SetWeightAttr(name string) {
+	d.weightAttr = name
+}
+
+// Names returns the node IDs of the last decoded graph,
+// Names()[v] is the ID of vertex v.
+func (d *Decoder[T]) Names() []string {
+	return d.names
+}
+
+// Decode reads the next graph from the underlying reader, a DOT file
+// may contain many graphs. It returns io.EOF if there are no more graphs.
+//
+// In undirected graphs each edge v -- w is decoded as the edges
+// v -> w and w -> v.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	if d.lex == nil {
+		d.lex = newLexer(d.r)
+	}
+	p := &parser[T]{
+		lex:         d.lex,
+		parseWeight: d.parseWeight,
+		weightAttr:  d.weightAttr,
+		ids:         make(map[string]int),
+	}
+	g, err := p.parseGraph()
+	if err != nil {
+		var serr *syntaxError
+		if errors.As(err, &serr) {
+			return nil, &encoding.SyntaxError{Line: serr.line, Err: serr.err}
+		}
+		return nil, err
+	}
+	d.names = p.names
+	return g, nil
+}
+
+type parser[T any] struct {
+	lex         *lexer
+	parseWeight func(string) (T, error)
+	weightAttr  string
+
+	directed bool
+	strict   bool
+	ids      map[string]int
+	names    []string
+	edges    []grafo.Edge[T]
+	// index of the edges of a strict graph.
+	index map[[2]int]int
+}
+
+// scope holds the default attributes of a graph or subgraph.
+type scope struct {
+	weight    string
+	hasWeight bool
+}
+
+func (p *parser[T]) errorf(line int, format string, args ...any) error {
+	return &syntaxError{line: line, err: fmt.Errorf(format, args...)}
+}
+
+func (p *parser[T]) unexpected(t token) error {
+	return p.errorf(t.line, "unexpected %v", t)
+}
+
+func (p *parser[T]) expect(kind tokenKind, what string) (token, error) {
+	t, err := p.lex.next()
+	if err != nil {
+		return t, err
+	}
+	if t.kind != kind {
+		return t, p.errorf(t.line, "unexpected %v, want %s", t, what)
+	}
+	return t, nil
+}
+
+// parseGraph parses:
+//
+//	graph : [ strict ] (graph | digraph) [ ID ] '{' stmt_list '}'
+func (p *parser[T]) parseGraph() (grafo.Graph[T], error) {
+	t, err := p.lex.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == tokEOF {
+		return nil, io.EOF
+	}
+	if t.keyword("strict") {
+		p.strict = true
+		p.index = make(map[[2]int]int)
+		if t, err = p.lex.next(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case t.keyword("digraph"):
+		p.directed = true
+	case t.keyword("graph"):
+		p.directed = false
+	default:
+		return nil, p.errorf(t.line, "unexpected %v, want graph or digraph", t)
+	}
+
+	if t, err = p.lex.peek(); err != nil {
+		return nil, err
+	}
+	if t.kind == tokID {
+		p.lex.next()
+	}
+	if _, err := p.expect(tokLBrace, "{"); err != nil {
+		return nil, err
+	}
+	if _, err := p.parseStmtList(&scope{}); err != nil {
+		return nil, err
+	}
+
+	g := multigraph.New[T](len(p.names))
+	for _, e := range p.edges {
+		if p.directed || e.V == e.W {
+			g.Add(e.V, e.W, e.Weight)
+		} else {
+			g.AddBoth(e.V, e.W, e.Weight)
+		}
+	}
+	return g, nil
+}
+
+// parseStmtList parses the statements until the closing '}' and
+// returns the vertices that appeared on them.
+//
+//	stmt_list : [ stmt [ ';' ] stmt_list ]
+func (p *parser[T]) parseStmtList(sc *scope) ([]int, error) {
+	var vertices []int
+	for {
+		t, err := p.lex.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch t.kind {
+		case tokRBrace:
+			p.lex.next()
+			return vertices, nil
+		case tokSemi:
+			p.lex.next()
+			continue
+		case tokEOF:
+			return nil, p.errorf(t.line, "unexpected EOF, want }")
+		}
+		vs, err := p.parseStmt(sc)
+		if err != nil {
+			return nil, err
+		}
+		vertices = append(vertices, vs...)
+	}
+}
+
+// parseStmt parses:
+//
+//	stmt      : node_stmt | edge_stmt | attr_stmt | ID '=' ID | subgraph
+//	attr_stmt : (graph | node | edge) attr_list
+//	node_stmt : node_id [ attr_list ]
+func (p *parser[T]) parseStmt(sc *scope) ([]int, error) {
+	t, err := p.lex.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t.keyword("graph"), t.keyword("node"), t.keyword("edge"):
+		p.lex.next()
+		weight, ok, err := p.parseAttrLists()
+		if err != nil {
+			return nil, err
+		}
+		if t.keyword("edge") && ok {
+			sc.weight, sc.hasWeight = weight, true
+		}
+		return nil, nil
+
+	case t.keyword("subgraph"), t.kind == tokLBrace:
+		vs, err := p.parseSubgraph(sc)
+		if err != nil {
+			return nil, err
+		}
+		return p.parseEdgeStmt(sc, vs)
+
+	case t.kind == tokID:
+		p.lex.next()
+		next, err := p.lex.peek()
+		if err != nil {
+			return nil, err
+		}
+		if next.kind == tokEqual {
+			// Graph attribute ID '=' ID.
+			p.lex.next()
+			_, err := p.expect(tokID, "ID")
+			return nil, err
+		}
+		v := p.vertex(t.text)
+		if err := p.skipPort(); err != nil {
+			return nil, err
+		}
+		return p.parseEdgeStmt(sc, []int{v})
+	}
+
+	return nil, p.unexpected(t)
+}
+
+// parseEdgeStmt parses the rest of an edge statement whose first
+// operand contains the vertices lhs, if there is no edge operator
+// it is a node statement or a lonely subgraph.
+//
+//	edge_stmt : (node_id | subgraph) edgeRHS [ attr_list ]
+//	edgeRHS   : edgeop (node_id | subgraph) [ edgeRHS ]
+func (p *parser[T]) parseEdgeStmt(sc *scope, lhs []int) ([]int, error) {
+	operands := [][]int{lhs}
+	vertices := lhs
+	line := 0
+	for {
+		t, err := p.lex.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind != tokArrow && t.kind != tokLine {
+			break
+		}
+		p.lex.next()
+		if line == 0 {
+			line = t.line
+		}
+		if p.directed && t.kind == tokLine {
+			return nil, p.errorf(t.line, "undirected edge in a digraph")
+		}
+		if !p.directed && t.kind == tokArrow {
+			return nil, p.errorf(t.line, "directed edge in a graph")
+		}
+
+		var vs []int
+		t, err = p.lex.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.keyword("subgraph"), t.kind == tokLBrace:
+			vs, err = p.parseSubgraph(sc)
+			if err != nil {
+				return nil, err
+			}
+		case t.kind == tokID && !t.keyword("node") && !t.keyword("edge") && !t.keyword("graph"):
+			p.lex.next()
+			vs = []int{p.vertex(t.text)}
+			if err := p.skipPort(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.unexpected(t)
+		}
+		operands = append(operands, vs)
+		vertices = append(vertices, vs...)
+	}
+
+	weight, ok, err := p.parseAttrLists()
+	if err != nil {
+		return nil, err
+	}
+	if len(operands) == 1 {
+		// Node statement.
+		return vertices, nil
+	}
+	if !ok {
+		weight, ok = sc.weight, sc.hasWeight
+	}
+	var wt T
+	if ok {
+		wt, err = p.parseWeight(weight)
+		if err != nil {
+			return nil, p.errorf(line, "%s: %w", p.weightAttr, err)
+		}
+	}
+	for i := 0; i+1 < len(operands); i++ {
+		for _, v := range operands[i] {
+			for _, w := range operands[i+1] {
+				p.addEdge(v, w, wt, ok)
+			}
+		}
+	}
+	return vertices, nil
+}
+
+// parseSubgraph parses:
+//
+//	subgraph : [ subgraph [ ID ] ] '{' stmt_list '}'
+func (p *parser[T]) parseSubgraph(sc *scope) ([]int, error) {
+	t, err := p.lex.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.keyword("subgraph") {
+		if t, err = p.lex.next(); err != nil {
+			return nil, err
+		}
+		if t.kind == tokID {
+			if t, err = p.lex.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if t.kind != tokLBrace {
+		return nil, p.errorf(t.line, "unexpected %v, want {", t)
+	}
+	// The subgraph inherits the defaults, but its changes
+	// don't leak to the enclosing graph.
+	inner := *sc
+	return p.parseStmtList(&inner)
+}
+
+// parseAttrLists parses zero or more attribute lists and returns the
+// last value assigned to the weight attribute.
+//
+//	attr_list : '[' [ a_list ] ']' [ attr_list ]
+//	a_list    : ID '=' ID [ (';' | ',') ] [ a_list ]
+func (p *parser[T]) parseAttrLists() (weight string, ok bool, err error) {
+	for {
+		t, err := p.lex.peek()
+		if err != nil {
+			return "", false, err
+		}
+		if t.kind != tokLBracket {
+			return weight, ok, nil
+		}
+		p.lex.next()
+		for {
+			t, err := p.lex.next()
+			if err != nil {
+				return "", false, err
+			}
+			if t.kind == tokRBracket {
+				break
+			}
+			if t.kind == tokSemi || t.kind == tokComma {
+				continue
+			}
+			if t.kind != tokID {
+				return "", false, p.unexpected(t)
+			}
+			if _, err := p.expect(tokEqual, "="); err != nil {
+				return "", false, err
+			}
+			val, err := p.expect(tokID, "ID")
+			if err != nil {
+				return "", false, err
+			}
+			if t.text == p.weightAttr {
+				weight, ok = val.text, true
+			}
+		}
+	}
+}
+
+// skipPort skips the optional port of a node_id.
+//
+//	port : ':' ID [ ':' compass_pt ]
+func (p *parser[T]) skipPort() error {
+	for range 2 {
+		t, err := p.lex.peek()
+		if err != nil {
+			return err
+		}
+		if t.kind != tokColon {
+			return nil
+		}
+		p.lex.next()
+		if _, err := p.expect(tokID, "port"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser[T]) vertex(id string) int {
+	v, ok := p.ids[id]
+	if !ok {
+		v = len(p.names)
+		p.ids[id] = v
+		p.names = append(p.names, id)
+	}
+	return v
+}
+
+// addEdge adds the edge v -> w, hasWeight tells if the statement
+// assigned its weight.
+func (p *parser[T]) addEdge(v, w int, weight T, hasWeight bool) {
+	e := grafo.Edge[T]{V: v, W: w, Weight: weight}
+	if !p.strict {
+		p.edges = append(p.edges, e)
+		return
+	}
+	// Strict graphs have no multi-edges, a repeated edge
+	// only updates the attributes it assigns.
+	key := [2]int{v, w}
+	if !p.directed && v > w {
+		key = [2]int{w, v}
+	}
+	if i, ok := p.index[key]; ok {
+		if hasWeight {
+			p.edges[i].Weight = weight
+		}
+		return
+	}
+	p.index[key] = len(p.edges)
+	p.edges = append(p.edges, e)
+}
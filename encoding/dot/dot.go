@@ -0,0 +1,254 @@
+// Package dot implements an encoder and a decoder for the Graphviz
+// DOT language.
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rschio/grafo"
+)
+
+// Attrs holds DOT attributes, e.g. Attrs{"color": "red", "label": "a"}.
+// Backslashes are written as they are, so Graphviz escapes such as
+// \l keep their meaning.
+type Attrs map[string]string
+
+// Encoder writes graphs in the DOT language.
+type Encoder[T any] struct {
+	w           io.Writer
+	fmtWeight   func(T) string
+	weightAttr  string
+	undirected  bool
+	vertexAttrs func(v int) Attrs
+	edgeAttrs   func(v, w int, weight T) Attrs
+	clusters    [][]int
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights. If fmtWeight is nil the
+// weights are not written.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:          w,
+		fmtWeight:  fmtWeight,
+		weightAttr: "weight",
+	}
+}
+
+// SetWeightAttr sets the edge attribute that holds the weight,
+// the default is "weight". Use "label" to show the weights
+// in the drawing.
+func (e *Encoder[T]) SetWeightAttr(name string) {
+	e.weightAttr = name
+}
+
+// SetUndirected makes the encoder write an undirected graph.
+// Each pair of edges v -> w and w -> v with the same attributes
+// is merged into a single edge v -- w, the remaining edges are
+// written as they are.
+func (e *Encoder[T]) SetUndirected(undirected bool) {
+	e.undirected = undirected
+}
+
+// SetVertexAttrs sets a function that returns the attributes of
+// each vertex, e.g. its label.
+func (e *Encoder[T]) SetVertexAttrs(attrs func(v int) Attrs) {
+	e.vertexAttrs = attrs
+}
+
+// SetEdgeAttrs sets a function that returns the attributes of each
+// edge, e.g. color, penwidth or label. If it returns the weight
+// attribute it overrides the formatted weight.
+func (e *Encoder[T]) SetEdgeAttrs(attrs func(v, w int, weight T) Attrs) {
+	e.edgeAttrs = attrs
+}
+
+// SetClusters groups the vertices of each clusters[i] in a
+// subgraph cluster_i, e.g. the components returned by
+// grafo.StrongComponents.
+func (e *Encoder[T]) SetClusters(clusters [][]int) {
+	e.clusters = clusters
+}
+
+// Encode writes g to the underlying writer. Every vertex v is
+// written as the node ID v, including isolated vertices. The node
+// statements come first and in order, so a Decoder reading the
+// output numbers the vertices as in g.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	bw := bufio.NewWriter(e.w)
+	kind, op := "digraph", "->"
+	if e.undirected {
+		kind, op = "graph", "--"
+	}
+	fmt.Fprintf(bw, "%s {\n", kind)
+
+	for v := range g.Order() {
+		var attrs Attrs
+		if e.vertexAttrs != nil {
+			attrs = e.vertexAttrs(v)
+		}
+		fmt.Fprintf(bw, "\t%d%s\n", v, formatAttrs(attrs, ""))
+	}
+
+	for i, cluster := range e.clusters {
+		fmt.Fprintf(bw, "\tsubgraph cluster_%d {\n", i)
+		for _, v := range cluster {
+			fmt.Fprintf(bw, "\t\t%d\n", v)
+		}
+		fmt.Fprintln(bw, "\t}")
+	}
+
+	for edge := range e.edges(g) {
+		fmt.Fprintf(bw, "\t%d %s %d%s\n", edge.v, op, edge.w, edge.attrs)
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// edge is an edge with its formatted attribute list.
+type edge struct {
+	v, w  int
+	attrs string
+}
+
+// edges returns the edges to write, merging the symmetric
+// pairs if the encoder is undirected.
+func (e *Encoder[T]) edges(g grafo.Graph[T]) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		if !e.undirected {
+			for v := range g.Order() {
+				for w, wt := range g.EdgesFrom(v) {
+					if !yield(edge{v, w, e.edgeAttrList(v, w, wt)}) {
+						return
+					}
+				}
+			}
+			return
+		}
+
+		var edges []edge
+		count := make(map[edge]int)
+		for v := range g.Order() {
+			for w, wt := range g.EdgesFrom(v) {
+				ed := edge{v, w, e.edgeAttrList(v, w, wt)}
+				edges = append(edges, ed)
+				count[ed]++
+			}
+		}
+		// merged counts the edges already written as part
+		// of a pair with its reverse.
+		merged := make(map[edge]int)
+		for _, ed := range edges {
+			if merged[ed] > 0 {
+				merged[ed]--
+				continue
+			}
+			count[ed]--
+			back := edge{ed.w, ed.v, ed.attrs}
+			if ed.v != ed.w && count[back] > 0 {
+				count[back]--
+				merged[back]++
+			}
+			if !yield(ed) {
+				return
+			}
+		}
+	}
+}
+
+func (e *Encoder[T]) edgeAttrList(v, w int, weight T) string {
+	var weightStr string
+	if e.fmtWeight != nil {
+		weightStr = e.fmtWeight(weight)
+	}
+	var attrs Attrs
+	if e.edgeAttrs != nil {
+		attrs = e.edgeAttrs(v, w, weight)
+	}
+	if e.fmtWeight != nil {
+		if _, ok := attrs[e.weightAttr]; !ok {
+			attrs = maps.Clone(attrs)
+			if attrs == nil {
+				attrs = make(Attrs, 1)
+			}
+			attrs[e.weightAttr] = weightStr
+		}
+	}
+	return formatAttrs(attrs, e.weightAttr)
+}
+
+// formatAttrs formats attrs as a DOT attribute list preceded by a
+// space. The attribute first is written first, the others in
+// lexicographical order.
+func formatAttrs(attrs Attrs, first string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	keys := slices.Sorted(maps.Keys(attrs))
+	if i := slices.Index(keys, first); i > 0 {
+		copy(keys[1:i+1], keys[:i])
+		keys[0] = first
+	}
+	var b strings.Builder
+	b.WriteString(" [")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quote(k))
+		b.WriteByte('=')
+		b.WriteString(quote(attrs[k]))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+var (
+	plainID   = regexp.MustCompile(`^[a-zA-Z_\x{80}-\x{10FFFF}][a-zA-Z_0-9\x{80}-\x{10FFFF}]*$`)
+	numeralID = regexp.MustCompile(`^-?(\.[0-9]+|[0-9]+(\.[0-9]*)?)$`)
+)
+
+// quote returns s as a DOT ID, quoting it if needed. The double
+// quotes are escaped, and so are the backslashes that would
+// otherwise escape a double quote or a line break, the other
+// backslashes start an escape sequence and are kept.
+func quote(s string) string {
+	if numeralID.MatchString(s) {
+		return s
+	}
+	if plainID.MatchString(s) {
+		switch strings.ToLower(s) {
+		case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		default:
+			return s
+		}
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			if i+1 < len(s) && !strings.ContainsRune("\"\n\r", rune(s[i+1])) {
+				b.WriteByte(c)
+				b.WriteByte(s[i+1])
+				i++
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
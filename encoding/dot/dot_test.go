@@ -0,0 +1,331 @@
+package dot
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantGraph string
+		wantNames []string
+	}{
+		{
+			name:      "chain",
+			in:        `digraph G { a -> b -> c [weight=2]; c -> a [weight="5"] }`,
+			wantGraph: "3 [(0 1):2 (1 2):2 (2 0):5]",
+			wantNames: []string{"a", "b", "c"},
+		},
+		{
+			name:      "undirected subgraph operand",
+			in:        "graph {\n a -- {b c} [weight=1]\n}",
+			wantGraph: "3 [{0 1}:1 {0 2}:1]",
+			wantNames: []string{"a", "b", "c"},
+		},
+		{
+			name: "edge defaults are scoped",
+			in: `digraph {
+				edge [weight=3]
+				a -> b
+				subgraph cluster_0 {
+					edge [weight=7]
+					b -> c
+				}
+				c -> a
+				a -> c [color=red, weight=1]
+			}`,
+			wantGraph: "3 [(0 1):3 (0 2):1 (1 2):7 (2 0):3]",
+			wantNames: []string{"a", "b", "c"},
+		},
+		{
+			name: "nodes, comments and IDs",
+			in: `# preprocessor line
+			/* block
+			   comment */
+			DiGraph {
+				rankdir = LR // graph attribute
+				node [shape=box]
+				"x y" [label="X"];
+				-1.5 -> "x y":p:n [weight=4]
+				<<b>html</b>> -> "con" + "cat" [weight=6]
+				z
+			}`,
+			wantGraph: "5 [(1 0):4 (2 3):6]",
+			wantNames: []string{"x y", "-1.5", "<b>html</b>", "concat", "z"},
+		},
+		{
+			name:      "escapes",
+			in:        `digraph { "a\"b" -> "c\\" -> "d\n" }`,
+			wantGraph: "3 [(0 1) (1 2)]",
+			wantNames: []string{`a"b`, `c\\`, `d\n`},
+		},
+		{
+			name:      "strict",
+			in:        `strict graph { a -- b [weight=1]; b -- a [weight=2]; a -- a }`,
+			wantGraph: "2 [(0 0) {0 1}:2]",
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name:      "strict repeated edge without weight",
+			in:        `strict digraph { a -> b [weight=3]; a -> b [color=red] }`,
+			wantGraph: "2 [(0 1):3]",
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name:      "multigraph",
+			in:        `digraph { a -> b [weight=1]; a -> b [weight=2] }`,
+			wantGraph: "2 [(0 1):1 (0 1):2]",
+			wantNames: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), strconv.Atoi)
+			g, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if got := grafo.String(g); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+			if diff := cmp.Diff(dec.Names(), tt.wantNames); diff != "" {
+				t.Errorf("Names() diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeWeightAttr(t *testing.T) {
+	in := `digraph { a -> b [label="1.5", weight=3] }`
+	dec := NewDecoder(strings.NewReader(in), func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+	dec.SetWeightAttr("label")
+	g, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(g), "2 [(0 1):1.5]"; got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"undirected edge in digraph", "digraph {\n a -- b\n}", 2},
+		{"directed edge in graph", "graph {\n\n a -> b\n}", 3},
+		{"missing brace", "digraph {\n a -> b\n", 3},
+		{"bad weight", "digraph {\n a -> b [weight=x]\n}", 2},
+		{"unterminated string", "digraph {\n \"a -> b\n}", 3},
+		{"not a graph", "node {}", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.in), strconv.Atoi).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestDecodeMany(t *testing.T) {
+	in := "digraph { a -> b }\ngraph { a -- b -- c }\n"
+	dec := NewDecoder(strings.NewReader(in), strconv.Atoi)
+	for _, want := range []int{2, 3} {
+		g, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Order() != want {
+			t.Errorf("got order %d, want %d", g.Order(), want)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("got error %v, want io.EOF", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[int](4)
+	g.Add(0, 1, 3)
+	g.Add(1, 2, -4)
+	g.Add(2, 0, 10)
+	g.Add(3, 3, 1)
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, strconv.Itoa).Encode(g); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewDecoder(&buf, strconv.Atoi).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(h), grafo.String(g); got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+}
+
+func TestEncodeEscapes(t *testing.T) {
+	labels := []string{`say "hi"\l`, `C:\dir\file`, `C:\`, `a\"b`, "a\\\nb"}
+	g := grafo.NewMutable[string](len(labels) + 1)
+	for i, l := range labels {
+		g.Add(0, i+1, l)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, func(s string) string { return s })
+	enc.SetWeightAttr("label")
+	if err := enc.Encode(g); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`label="say \"hi\"\l"`, `label="C:\dir\file"`, `label="C:\\"`, `label="a\\\"b"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("got:\n%s\nwant the attribute %s", buf.String(), want)
+		}
+	}
+
+	dec := NewDecoder(&buf, func(s string) (string, error) { return s, nil })
+	dec.SetWeightAttr("label")
+	h, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The added backslashes are Graphviz escapes for a single one.
+	want := []string{`say "hi"\l`, `C:\dir\file`, `C:\\`, `a\\"b`, "a\\\\\nb"}
+	got := make([]string, len(labels))
+	for w, l := range h.EdgesFrom(0) {
+		got[w-1] = l
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("decoded labels diff: %s", diff)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	g := grafo.NewMutable[int](5)
+	g.AddBoth(0, 1, 3)
+	g.Add(1, 2, 4)
+	g.Add(2, 1, 5)
+	g.Add(3, 3, 1)
+
+	t.Run("digraph", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf, strconv.Itoa)
+		if err := enc.Encode(grafo.Sort(g)); err != nil {
+			t.Fatal(err)
+		}
+		want := `digraph {
+	0
+	1
+	2
+	3
+	4
+	0 -> 1 [weight=3]
+	1 -> 0 [weight=3]
+	1 -> 2 [weight=4]
+	2 -> 1 [weight=5]
+	3 -> 3 [weight=1]
+}
+`
+		if diff := cmp.Diff(buf.String(), want); diff != "" {
+			t.Errorf("Encode() diff: %s", diff)
+		}
+	})
+
+	t.Run("undirected with attributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf, strconv.Itoa)
+		enc.SetUndirected(true)
+		enc.SetWeightAttr("label")
+		enc.SetVertexAttrs(func(v int) Attrs {
+			if v == 4 {
+				return Attrs{"label": "isolated vertex", "shape": "box"}
+			}
+			return nil
+		})
+		enc.SetEdgeAttrs(func(v, w, weight int) Attrs {
+			if weight == 4 {
+				return Attrs{"color": "red", "penwidth": "2.5"}
+			}
+			return nil
+		})
+		enc.SetClusters([][]int{{0, 1}, {2}})
+		if err := enc.Encode(grafo.Sort(g)); err != nil {
+			t.Fatal(err)
+		}
+		want := `graph {
+	0
+	1
+	2
+	3
+	4 [label="isolated vertex", shape=box]
+	subgraph cluster_0 {
+		0
+		1
+	}
+	subgraph cluster_1 {
+		2
+	}
+	0 -- 1 [label=3]
+	1 -- 2 [label=4, color=red, penwidth=2.5]
+	2 -- 1 [label=5]
+	3 -- 3 [label=1]
+}
+`
+		if diff := cmp.Diff(buf.String(), want); diff != "" {
+			t.Errorf("Encode() diff: %s", diff)
+		}
+
+		dec := NewDecoder(&buf, strconv.Atoi)
+		dec.SetWeightAttr("label")
+		h, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := grafo.String(h), "5 [{0 1}:3 {1 2}:4 {1 2}:5 (3 3):1]"; got != want {
+			t.Errorf("decoded %s, want %s", got, want)
+		}
+		if diff := cmp.Diff(dec.Names(), []string{"0", "1", "2", "3", "4"}); diff != "" {
+			t.Errorf("Names() diff: %s", diff)
+		}
+	})
+
+	t.Run("quoting", func(t *testing.T) {
+		g := grafo.NewMutable[string](2)
+		g.Add(0, 1, `say "hi"`)
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf, func(s string) string { return s })
+		enc.SetVertexAttrs(func(v int) Attrs { return Attrs{"label": "node"} })
+		if err := enc.Encode(g); err != nil {
+			t.Fatal(err)
+		}
+		want := `digraph {
+	0 [label="node"]
+	1 [label="node"]
+	0 -> 1 [weight="say \"hi\""]
+}
+`
+		if diff := cmp.Diff(buf.String(), want); diff != "" {
+			t.Errorf("Encode() diff: %s", diff)
+		}
+	})
+}
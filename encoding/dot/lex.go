@@ -0,0 +1,400 @@
+package dot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokID
+	tokLBrace   // {
+	tokRBrace   // }
+	tokLBracket // [
+	tokRBracket // ]
+	tokSemi     // ;
+	tokComma    // ,
+	tokEqual    // =
+	tokColon    // :
+	tokArrow    // ->
+	tokLine     // --
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// plain is true for IDs that are neither quoted nor HTML strings,
+	// only plain IDs can be keywords.
+	plain bool
+	line  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "EOF"
+	case tokID:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return t.text
+	}
+}
+
+// keyword tells if t is the keyword kw, keywords are case-insensitive.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokID && t.plain && strings.EqualFold(t.text, kw)
+}
+
+// lexer splits a DOT input into tokens.
+type lexer struct {
+	r      *bufio.Reader
+	line   int
+	peeked *token
+	// bol is true if no token was read in the current line,
+	// it is used to identify preprocessor lines starting with '#'.
+	bol bool
+	// prevBol is the value of bol before the last '\n' was read,
+	// it is restored when the '\n' is unread.
+	prevBol bool
+}
+
+func newLexer(r io.Reader) *lexer {
+	return &lexer{r: bufio.NewReader(r), line: 1, bol: true}
+}
+
+func (l *lexer) peek() (token, error) {
+	if l.peeked == nil {
+		t, err := l.scan()
+		if err != nil {
+			return t, err
+		}
+		l.peeked = &t
+	}
+	return *l.peeked, nil
+}
+
+func (l *lexer) next() (token, error) {
+	t, err := l.peek()
+	l.peeked = nil
+	return t, err
+}
+
+func (l *lexer) read() (rune, error) {
+	c, _, err := l.r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if c == '\n' {
+		l.line++
+		l.prevBol, l.bol = l.bol, true
+	}
+	return c, nil
+}
+
+func (l *lexer) unread(c rune) {
+	if c == '\n' {
+		l.line--
+		l.bol = l.prevBol
+	}
+	l.r.UnreadRune()
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return &syntaxError{line: l.line, err: fmt.Errorf(format, args...)}
+}
+
+// syntaxError is converted to an encoding.SyntaxError by the Decoder.
+type syntaxError struct {
+	line int
+	err  error
+}
+
+func (e *syntaxError) Error() string { return e.err.Error() }
+
+func (l *lexer) scan() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return token{kind: tokEOF, line: l.line}, nil
+		}
+		return token{}, err
+	}
+	l.bol = false
+	line := l.line
+	c, err := l.read()
+	if err != nil {
+		return token{}, err
+	}
+	punct := func(kind tokenKind) (token, error) {
+		return token{kind: kind, text: string(c), line: line}, nil
+	}
+
+	switch {
+	case c == '{':
+		return punct(tokLBrace)
+	case c == '}':
+		return punct(tokRBrace)
+	case c == '[':
+		return punct(tokLBracket)
+	case c == ']':
+		return punct(tokRBracket)
+	case c == ';':
+		return punct(tokSemi)
+	case c == ',':
+		return punct(tokComma)
+	case c == '=':
+		return punct(tokEqual)
+	case c == ':':
+		return punct(tokColon)
+	case c == '-':
+		d, err := l.read()
+		if err == nil {
+			switch {
+			case d == '>':
+				return token{kind: tokArrow, text: "->", line: line}, nil
+			case d == '-':
+				return token{kind: tokLine, text: "--", line: line}, nil
+			case d == '.' || isDigit(d):
+				l.unread(d)
+				return l.scanNumeral("-", line)
+			}
+			l.unread(d)
+		}
+		return token{}, l.errorf("unexpected '-'")
+	case c == '.' || isDigit(c):
+		return l.scanNumeral(string(c), line)
+	case c == '"':
+		return l.scanQuoted(line)
+	case c == '<':
+		return l.scanHTML(line)
+	case isIDStart(c):
+		return l.scanID(c, line)
+	}
+	return token{}, l.errorf("unexpected character %q", c)
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return err
+		}
+		switch {
+		case unicode.IsSpace(c):
+		case c == '#' && l.bol:
+			if err := l.skipLine(); err != nil {
+				return err
+			}
+		case c == '/':
+			d, err := l.read()
+			if err != nil {
+				return l.errorf("unexpected '/'")
+			}
+			switch d {
+			case '/':
+				if err := l.skipLine(); err != nil {
+					return err
+				}
+			case '*':
+				if err := l.skipBlockComment(); err != nil {
+					return err
+				}
+			default:
+				return l.errorf("unexpected '/'")
+			}
+		default:
+			l.unread(c)
+			return nil
+		}
+	}
+}
+
+func (l *lexer) skipLine() error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return err
+		}
+		if c == '\n' {
+			return nil
+		}
+	}
+}
+
+func (l *lexer) skipBlockComment() error {
+	prev := rune(0)
+	for {
+		c, err := l.read()
+		if err != nil {
+			return l.errorf("unterminated comment")
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
+
+func (l *lexer) scanID(first rune, line int) (token, error) {
+	var b strings.Builder
+	b.WriteRune(first)
+	for {
+		c, err := l.read()
+		if err != nil {
+			break
+		}
+		if !isIDStart(c) && !isDigit(c) {
+			l.unread(c)
+			break
+		}
+		b.WriteRune(c)
+	}
+	return token{kind: tokID, text: b.String(), plain: true, line: line}, nil
+}
+
+func (l *lexer) scanNumeral(prefix string, line int) (token, error) {
+	var b strings.Builder
+	b.WriteString(prefix)
+	dot := strings.HasSuffix(prefix, ".")
+	digits := isDigit(rune(prefix[len(prefix)-1]))
+	for {
+		c, err := l.read()
+		if err != nil {
+			break
+		}
+		if c == '.' && !dot {
+			dot = true
+		} else if isDigit(c) {
+			digits = true
+		} else {
+			l.unread(c)
+			break
+		}
+		b.WriteRune(c)
+	}
+	if !digits {
+		return token{}, l.errorf("invalid numeral %q", b.String())
+	}
+	return token{kind: tokID, text: b.String(), line: line}, nil
+}
+
+// scanQuoted scans a double-quoted string, the opening quote was already
+// consumed. Quoted strings can be concatenated with '+'.
+func (l *lexer) scanQuoted(line int) (token, error) {
+	var b strings.Builder
+	for {
+		if err := l.scanQuotedPart(&b); err != nil {
+			return token{}, err
+		}
+		if !l.concatenation() {
+			break
+		}
+	}
+	return token{kind: tokID, text: b.String(), line: line}, nil
+}
+
+func (l *lexer) scanQuotedPart(b *strings.Builder) error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return l.errorf("unterminated string")
+		}
+		switch c {
+		case '"':
+			return nil
+		case '\\':
+			d, err := l.read()
+			if err != nil {
+				return l.errorf("unterminated string")
+			}
+			switch d {
+			case '"':
+				b.WriteRune(d)
+			case '\n':
+				// Line continuation.
+			case '\r':
+				// Line continuation with CRLF line ending.
+				if e, err := l.read(); err == nil && e != '\n' {
+					l.unread(e)
+				}
+			default:
+				// Other escapes such as \\, \n or \l are meaningful
+				// to Graphviz, keep them.
+				b.WriteRune('\\')
+				b.WriteRune(d)
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+// concatenation consumes a '+' followed by a quoted string opening
+// and reports whether it was found.
+func (l *lexer) concatenation() bool {
+	// Only spaces are allowed around '+', there is no way to push back
+	// more than one rune, so the lookahead is done through Peek.
+	for i := 1; ; i++ {
+		buf, err := l.r.Peek(i)
+		if err != nil {
+			return false
+		}
+		c := rune(buf[i-1])
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+		if c != '+' {
+			return false
+		}
+		for j := i + 1; ; j++ {
+			buf, err := l.r.Peek(j)
+			if err != nil {
+				return false
+			}
+			c := rune(buf[j-1])
+			if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+				continue
+			}
+			if c != '"' {
+				return false
+			}
+			for range j {
+				l.read()
+			}
+			return true
+		}
+	}
+}
+
+// scanHTML scans an HTML string, the opening '<' was already consumed.
+func (l *lexer) scanHTML(line int) (token, error) {
+	var b strings.Builder
+	depth := 1
+	for {
+		c, err := l.read()
+		if err != nil {
+			return token{}, l.errorf("unterminated HTML string")
+		}
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		}
+		if depth == 0 {
+			return token{kind: tokID, text: b.String(), line: line}, nil
+		}
+		b.WriteRune(c)
+	}
+}
+
+func isDigit(c rune) bool { return '0' <= c && c <= '9' }
+
+func isIDStart(c rune) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,309 @@
+// Package edgelist implements delimited edge lists, like CSV and
+// TSV files, where each record holds one edge:
+//
+//	source,target,weight
+//	a,b,3
+//	b,c,4
+//
+// The vertices are identified by arbitrary names that are mapped
+// to the vertices 0, 1, ..., n-1 in the order they first appear.
+package edgelist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Encoder writes graphs as edge lists.
+type Encoder[T any] struct {
+	w         io.Writer
+	fmtWeight func(T) string
+	delimiter rune
+	header    []string
+	names     []string
+}
+
+// NewEncoder returns an Encoder that writes to w and uses fmtWeight
+// to format the weights. If fmtWeight is nil the weights are not
+// written. The default delimiter is a comma.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+		delimiter: ',',
+	}
+}
+
+// SetDelimiter sets the field delimiter, e.g. '\t' for TSV.
+func (e *Encoder[T]) SetDelimiter(r rune) {
+	e.delimiter = r
+}
+
+// SetHeader makes the encoder write a header row with the names
+// of the columns, source, target and, if the weights are written,
+// weight.
+func (e *Encoder[T]) SetHeader(source, target, weight string) {
+	e.header = []string{source, target, weight}
+}
+
+// SetNames sets the names of the vertices, names[v] is written
+// in place of v. By default the vertices are written as numbers.
+func (e *Encoder[T]) SetNames(names []string) {
+	e.names = names
+}
+
+// Encode writes g to the underlying writer.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	if e.names != nil && len(e.names) < g.Order() {
+		return fmt.Errorf("got %d names for %d vertices", len(e.names), g.Order())
+	}
+	name := func(v int) string {
+		if e.names != nil {
+			return e.names[v]
+		}
+		return strconv.Itoa(v)
+	}
+
+	cw := csv.NewWriter(e.w)
+	cw.Comma = e.delimiter
+	if e.header != nil {
+		header := e.header
+		if e.fmtWeight == nil {
+			header = header[:2]
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	record := make([]string, 2, 3)
+	for v := range g.Order() {
+		for w, wt := range g.EdgesFrom(v) {
+			record = append(record[:0], name(v), name(w))
+			if e.fmtWeight != nil {
+				record = append(record, e.fmtWeight(wt))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Decoder reads graphs from edge lists.
+type Decoder[T any] struct {
+	r           io.Reader
+	parseWeight func(string) (T, error)
+	delimiter   rune
+	comment     rune
+	header      bool
+	undirected  bool
+
+	// Columns, by index or, if the index is -1, by name.
+	columns     [3]int
+	columnNames [3]string
+
+	names []string
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weights.
+//
+// By default the records are separated by commas and have no
+// header, the source, target and weight are the columns 0, 1 and 2.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+		delimiter:   ',',
+		columns:     [3]int{0, 1, 2},
+	}
+}
+
+// SetDelimiter sets the field delimiter, e.g. '\t' for TSV.
+// The delimiter ' ' splits the fields on runs of white space,
+// like strings.Fields, and doesn't support quoted fields.
+func (d *Decoder[T]) SetDelimiter(r rune) {
+	d.delimiter = r
+}
+
+// SetComment sets the character that starts comment lines,
+// by default there are no comments.
+func (d *Decoder[T]) SetComment(r rune) {
+	d.comment = r
+}
+
+// SetHeader tells if the first record is a header with the names
+// of the columns, which is skipped.
+func (d *Decoder[T]) SetHeader(header bool) {
+	d.header = header
+}
+
+// SetColumns sets the 0 indexed columns of the source, target and
+// weight. If weight is -1 the records have no weight and the edges
+// have the zero value of T. It returns an error if a column is out
+// of range and leaves the columns unchanged.
+func (d *Decoder[T]) SetColumns(source, target, weight int) error {
+	if source < 0 || target < 0 || weight < -1 {
+		return fmt.Errorf("invalid columns %d, %d, %d", source, target, weight)
+	}
+	d.columns = [3]int{source, target, weight}
+	d.columnNames = [3]string{}
+	return nil
+}
+
+// SetColumnNames sets the columns of the source, target and weight
+// by their names in the header, it implies SetHeader(true). If weight
+// is "" the records have no weight and the edges have the zero
+// value of T. It returns an error if source or target is "" and
+// leaves the columns unchanged.
+func (d *Decoder[T]) SetColumnNames(source, target, weight string) error {
+	if source == "" || target == "" {
+		return errors.New("empty source or target column name")
+	}
+	d.header = true
+	d.columns = [3]int{-1, -1, -1}
+	d.columnNames = [3]string{source, target, weight}
+	return nil
+}
+
+// SetUndirected makes the decoder read each record v, w as the
+// edges v -> w and w -> v. Self-loops are added once.
+func (d *Decoder[T]) SetUndirected(undirected bool) {
+	d.undirected = undirected
+}
+
+// Names returns the vertex names of the last decoded graph,
+// Names()[v] is the name of vertex v.
+func (d *Decoder[T]) Names() []string { return d.names }
+
+type edge[T any] struct {
+	v, w   int
+	weight T
+}
+
+// Decode reads a graph from the underlying reader.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	next := d.records()
+
+	columns := d.columns
+	if d.header {
+		header, line, err := next()
+		if err == io.EOF {
+			return nil, &encoding.SyntaxError{Line: 1, Err: errors.New("missing header")}
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range d.columnNames {
+			if name == "" {
+				continue
+			}
+			columns[i] = slices.Index(header, name)
+			if columns[i] < 0 {
+				err := fmt.Errorf("missing column %q in the header", name)
+				return nil, &encoding.SyntaxError{Line: line, Err: err}
+			}
+		}
+	}
+	ncols := max(columns[0], columns[1], columns[2]) + 1
+
+	ids := make(map[string]int)
+	var names []string
+	vertex := func(name string) int {
+		v, ok := ids[name]
+		if !ok {
+			v = len(names)
+			ids[name] = v
+			names = append(names, name)
+		}
+		return v
+	}
+
+	var edges []edge[T]
+	for {
+		record, line, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < ncols {
+			err := fmt.Errorf("got %d fields in one record, want at least %d", len(record), ncols)
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		e := edge[T]{v: vertex(record[columns[0]]), w: vertex(record[columns[1]])}
+		if columns[2] >= 0 {
+			e.weight, err = d.parseWeight(strings.TrimSpace(record[columns[2]]))
+			if err != nil {
+				return nil, &encoding.SyntaxError{Line: line, Err: err}
+			}
+		}
+		edges = append(edges, e)
+	}
+
+	g := multigraph.New[T](len(names))
+	for _, e := range edges {
+		if d.undirected && e.v != e.w {
+			g.AddBoth(e.v, e.w, e.weight)
+		} else {
+			g.Add(e.v, e.w, e.weight)
+		}
+	}
+	d.names = names
+	return g, nil
+}
+
+// records returns a function that reads the next record and its line.
+func (d *Decoder[T]) records() func() ([]string, int, error) {
+	if d.delimiter == ' ' {
+		sc := bufio.NewScanner(d.r)
+		line := 0
+		return func() ([]string, int, error) {
+			for sc.Scan() {
+				line++
+				text := strings.TrimSpace(sc.Text())
+				if text == "" || (d.comment != 0 && strings.HasPrefix(text, string(d.comment))) {
+					continue
+				}
+				return strings.Fields(text), line, nil
+			}
+			if err := sc.Err(); err != nil {
+				return nil, 0, err
+			}
+			return nil, 0, io.EOF
+		}
+	}
+
+	cr := csv.NewReader(d.r)
+	cr.Comma = d.delimiter
+	cr.Comment = d.comment
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+	return func() ([]string, int, error) {
+		record, err := cr.Read()
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				return nil, 0, &encoding.SyntaxError{Line: perr.Line, Err: perr.Err}
+			}
+			return nil, 0, err
+		}
+		line, _ := cr.FieldPos(0)
+		return record, line, nil
+	}
+}
@@ -0,0 +1,170 @@
+package edgelist
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		setup     func(d *Decoder[int])
+		wantGraph string
+		wantNames []string
+	}{
+		{
+			name:      "csv",
+			in:        "a,b,3\nb, c ,4\n\"x,y\",a,1\n",
+			setup:     func(d *Decoder[int]) {},
+			wantGraph: "4 [(0 1):3 (1 2):4 (3 0):1]",
+			wantNames: []string{"a", "b", "c ", "x,y"},
+		},
+		{
+			name: "tsv with header",
+			in:   "from\tto\tcost\n1\t2\t5\n2\t1\t6\n",
+			setup: func(d *Decoder[int]) {
+				d.SetDelimiter('\t')
+				d.SetHeader(true)
+			},
+			wantGraph: "2 [(0 1):5 (1 0):6]",
+			wantNames: []string{"1", "2"},
+		},
+		{
+			name: "white space",
+			in:   "# comment\n  10   20\t7\n\n20  20 1\n",
+			setup: func(d *Decoder[int]) {
+				d.SetDelimiter(' ')
+				d.SetComment('#')
+			},
+			wantGraph: "2 [(0 1):7 (1 1):1]",
+			wantNames: []string{"10", "20"},
+		},
+		{
+			name: "column names",
+			in:   "weight,id,dst,src\n9,e1,b,a\n8,e2,c,b\n",
+			setup: func(d *Decoder[int]) {
+				d.SetColumnNames("src", "dst", "weight")
+			},
+			wantGraph: "3 [(0 1):9 (1 2):8]",
+			wantNames: []string{"a", "b", "c"},
+		},
+		{
+			name: "undirected without weights",
+			in:   "u;v;ignored\nu;u;x\n",
+			setup: func(d *Decoder[int]) {
+				d.SetDelimiter(';')
+				d.SetColumns(0, 1, -1)
+				d.SetUndirected(true)
+			},
+			wantGraph: "2 [(0 0) {0 1}]",
+			wantNames: []string{"u", "v"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), strconv.Atoi)
+			tt.setup(dec)
+			g, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grafo.String(g); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+			if diff := cmp.Diff(dec.Names(), tt.wantNames); diff != "" {
+				t.Errorf("Names() diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		setup    func(d *Decoder[int])
+		wantLine int
+	}{
+		{"missing field", "a,b,1\n\na,b\n", func(d *Decoder[int]) {}, 3},
+		{"bad weight", "a,b,1\na,b,x\n", func(d *Decoder[int]) {}, 2},
+		{"bad quote", "a,b,1\na,\"b,1\n", func(d *Decoder[int]) {}, 2},
+		{"missing header", "", func(d *Decoder[int]) { d.SetHeader(true) }, 1},
+		{"missing column", "s,t\na,b\n", func(d *Decoder[int]) { d.SetColumnNames("s", "t", "w") }, 1},
+		{"white space bad weight", "a b 1\n\na b x\n", func(d *Decoder[int]) { d.SetDelimiter(' ') }, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), strconv.Atoi)
+			tt.setup(dec)
+			_, err := dec.Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestSetColumns(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b,1\n"), strconv.Atoi)
+	for _, cols := range [][3]int{{-1, 1, 2}, {0, -1, 2}, {0, 1, -2}} {
+		if err := dec.SetColumns(cols[0], cols[1], cols[2]); err == nil {
+			t.Errorf("SetColumns%v got nil error", cols)
+		}
+	}
+	if err := dec.SetColumnNames("", "t", "w"); err == nil {
+		t.Error("SetColumnNames with an empty source got nil error")
+	}
+	g, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(g), "2 [(0 1):1]"; got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[int](3)
+	g.Add(0, 1, 3)
+	g.Add(1, 2, -4)
+	g.Add(2, 2, 1)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, strconv.Itoa)
+	enc.SetDelimiter('\t')
+	enc.SetHeader("source", "target", "weight")
+	enc.SetNames([]string{"a", "b b", "c\td"})
+	if err := enc.Encode(grafo.Sort(g)); err != nil {
+		t.Fatal(err)
+	}
+	want := "source\ttarget\tweight\na\tb b\t3\nb b\t\"c\td\"\t-4\n\"c\td\"\t\"c\td\"\t1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+
+	dec := NewDecoder(&buf, strconv.Atoi)
+	dec.SetDelimiter('\t')
+	dec.SetHeader(true)
+	h, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(h), grafo.String(g); got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+	if diff := cmp.Diff(dec.Names(), []string{"a", "b b", "c\td"}); diff != "" {
+		t.Errorf("Names() diff: %s", diff)
+	}
+}
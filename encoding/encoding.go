@@ -0,0 +1,52 @@
+// Package encoding defines interfaces shared by the packages that
+// convert graphs to and from textual and binary representations.
+//
+// Each format lives in its own subpackage, e.g. encoding/simple,
+// encoding/gr and encoding/dot.
+package encoding
+
+import (
+	"fmt"
+
+	"github.com/rschio/grafo"
+)
+
+// Encoder is the interface implemented by types that can write
+// a graph in some format.
+type Encoder[T any] interface {
+	Encode(grafo.Graph[T]) error
+}
+
+// Decoder is the interface implemented by types that can read
+// a graph in some format.
+type Decoder[T any] interface {
+	Decode() (grafo.Graph[T], error)
+}
+
+// Transform decodes a graph from `from` and encodes it using `to`.
+//
+// The output is not required to be byte by byte equal to the input
+// even if both use the same format, e.g. the order of the edges may
+// change, but decoding it must result in an equivalent graph.
+func Transform[T any](to Encoder[T], from Decoder[T]) error {
+	g, err := from.Decode()
+	if err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+	if err := to.Encode(g); err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	return nil
+}
+
+// SyntaxError describes malformed input found by a Decoder.
+type SyntaxError struct {
+	Line int   // Line of the input where the error occurred, starting at 1.
+	Err  error // The underlying error.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
@@ -2,11 +2,13 @@ package encoding_test
 
 import (
 	"bytes"
+	"errors"
 	"strconv"
+	"strings"
 	"testing"
 
-	"github.com/rschio/grafo/internal/encoding"
-	"github.com/rschio/grafo/internal/encoding/simple"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/encoding/simple"
 	"github.com/rschio/grafo/internal/multigraph"
 )
 
@@ -39,3 +41,20 @@ func TestTransform(t *testing.T) {
 		t.Fatalf("got diferent encodes from simple encode and transform:\n%s\n%s", w.Bytes(), encoded)
 	}
 }
+
+func TestSyntaxErrorLine(t *testing.T) {
+	in := "3\n0 1 1\n\n1 2 x\n"
+	dec := simple.NewDecoder[int](strings.NewReader(in), strconv.Atoi)
+
+	_, err := dec.Decode()
+	var serr *encoding.SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Decode() error = %v, want *SyntaxError", err)
+	}
+	if serr.Line != 4 {
+		t.Errorf("got error at line %d, want 4", serr.Line)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("error %v should wrap strconv.ErrSyntax", err)
+	}
+}
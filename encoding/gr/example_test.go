@@ -0,0 +1,60 @@
+package gr_test
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding/gr"
+)
+
+// This example computes the out-degree of the vertices while reading
+// the arcs, without keeping the graph in memory.
+func ExampleDecoder_Arcs() {
+	const in = `c A small road network.
+p sp 4 5
+a 1 2 803
+a 1 3 158
+a 2 1 803
+a 3 1 158
+a 3 4 774
+`
+	dec := gr.NewDecoder(strings.NewReader(in), strconv.Atoi)
+	n, _, err := dec.Problem()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	degree := make([]int, n)
+	for e, err := range dec.Arcs() {
+		if err != nil {
+			log.Fatal(err)
+		}
+		degree[e.V]++
+	}
+	fmt.Println(degree)
+	// Output:
+	// [2 1 2 0]
+}
+
+func ExampleDecoder_DecodeMaxFlow() {
+	const in = `p max 4 5
+n 1 s
+n 4 t
+a 1 2 4
+a 1 3 2
+a 2 3 1
+a 2 4 2
+a 3 4 4
+`
+	p, err := gr.NewDecoder(strings.NewReader(in), strconv.Atoi).DecodeMaxFlow()
+	if err != nil {
+		log.Fatal(err)
+	}
+	flow, _ := grafo.MaxFlow(p.Graph, p.Source, p.Sink)
+	fmt.Println(flow)
+	// Output:
+	// 5
+}
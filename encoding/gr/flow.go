@@ -0,0 +1,252 @@
+package gr
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// MaxFlowProblem is a DIMACS maximum flow problem (p max), the
+// capacity of each arc is the weight of the edge in Graph.
+//
+// A p max file has the format:
+//
+//	p max V E
+//	n s s
+//	n t t
+//	a v w capacity
+type MaxFlowProblem[T any] struct {
+	Graph  grafo.Graph[T]
+	Source int
+	Sink   int
+}
+
+// Arc describes an arc of a minimum cost flow problem.
+type Arc[T any] struct {
+	Lower    T // Minimum flow of the arc.
+	Capacity T // Maximum flow of the arc.
+	Cost     T // Cost per unit of flow.
+}
+
+// MinCostProblem is a DIMACS minimum cost flow problem (p min).
+// Supply[v] is the supply of v if positive or its demand if negative,
+// vertices without a node line have zero supply.
+//
+// A p min file has the format:
+//
+//	p min V E
+//	n v supply
+//	a v w lower capacity cost
+type MinCostProblem[T any] struct {
+	Graph  grafo.Graph[Arc[T]]
+	Supply []T
+}
+
+// DecodeMaxFlow reads a maximum flow problem from the underlying reader.
+// The vertices are converted to 0 indexed.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) DecodeMaxFlow() (*MaxFlowProblem[T], error) {
+	fields, err := d.r.problem("max", 2)
+	if err != nil {
+		return nil, err
+	}
+	n, m := fields[0], fields[1]
+
+	g := multigraph.New[T](n)
+	p := &MaxFlowProblem[T]{Graph: g, Source: -1, Sink: -1}
+	node := func(fields [][]byte) error {
+		if len(fields) != 2 {
+			return d.r.errorf("got %d elements in node line, want 2", len(fields))
+		}
+		v, err := d.r.vertex(fields[0], n)
+		if err != nil {
+			return err
+		}
+		var dst *int
+		switch string(fields[1]) {
+		case "s":
+			dst = &p.Source
+		case "t":
+			dst = &p.Sink
+		default:
+			return d.r.errorf("got node designator %q, want s or t", fields[1])
+		}
+		if *dst != -1 {
+			return d.r.errorf("repeated node designator %q", fields[1])
+		}
+		*dst = v
+		return nil
+	}
+	arc := func(v, w int, fields [][]byte) error {
+		if len(fields) != 1 {
+			return d.r.errorf("got %d elements in arc line, want 3", len(fields)+2)
+		}
+		capacity, err := d.parseWeight(string(fields[0]))
+		if err != nil {
+			return d.r.wrap(err)
+		}
+		g.Add(v, w, capacity)
+		return nil
+	}
+	if err := d.readFlow(n, m, node, arc); err != nil {
+		return nil, err
+	}
+
+	if p.Source == -1 {
+		return nil, d.r.errorf("missing source node line")
+	}
+	if p.Sink == -1 {
+		return nil, d.r.errorf("missing sink node line")
+	}
+	return p, nil
+}
+
+// DecodeMinCost reads a minimum cost flow problem from the underlying
+// reader. The vertices are converted to 0 indexed.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) DecodeMinCost() (*MinCostProblem[T], error) {
+	fields, err := d.r.problem("min", 2)
+	if err != nil {
+		return nil, err
+	}
+	n, m := fields[0], fields[1]
+
+	g := multigraph.New[Arc[T]](n)
+	p := &MinCostProblem[T]{Graph: g, Supply: make([]T, n)}
+	node := func(fields [][]byte) error {
+		if len(fields) != 2 {
+			return d.r.errorf("got %d elements in node line, want 2", len(fields))
+		}
+		v, err := d.r.vertex(fields[0], n)
+		if err != nil {
+			return err
+		}
+		supply, err := d.parseWeight(string(fields[1]))
+		if err != nil {
+			return d.r.wrap(err)
+		}
+		p.Supply[v] = supply
+		return nil
+	}
+	arc := func(v, w int, fields [][]byte) error {
+		if len(fields) != 3 {
+			return d.r.errorf("got %d elements in arc line, want 5", len(fields)+2)
+		}
+		var vals [3]T
+		for i, f := range fields {
+			val, err := d.parseWeight(string(f))
+			if err != nil {
+				return d.r.wrap(err)
+			}
+			vals[i] = val
+		}
+		g.Add(v, w, Arc[T]{Lower: vals[0], Capacity: vals[1], Cost: vals[2]})
+		return nil
+	}
+	if err := d.readFlow(n, m, node, arc); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// readFlow reads the node and arc lines of a flow problem with n
+// vertices and m arcs. The node function receives the fields after
+// the 'n' and arc the fields after the 0 indexed vertices v and w.
+func (d *Decoder[T]) readFlow(n, m int,
+	node func(fields [][]byte) error,
+	arc func(v, w int, fields [][]byte) error,
+) error {
+	count := 0
+	for {
+		line, err := d.r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		fields := bytes.Fields(line)
+		switch string(fields[0]) {
+		case "n":
+			if err := node(fields[1:]); err != nil {
+				return err
+			}
+		case "a":
+			if len(fields) < 3 {
+				return d.r.errorf("got %d elements in arc line", len(fields)-1)
+			}
+			v, err := d.r.vertex(fields[1], n)
+			if err != nil {
+				return err
+			}
+			w, err := d.r.vertex(fields[2], n)
+			if err != nil {
+				return err
+			}
+			if count++; count > m {
+				return d.r.errorf("got more than %d arcs declared in the problem line", m)
+			}
+			if err := arc(v, w, fields[3:]); err != nil {
+				return err
+			}
+		default:
+			return d.r.errorf("wrong format: %s", line)
+		}
+	}
+	if count != m {
+		return d.r.errorf("got %d arcs, want %d declared in the problem line", count, m)
+	}
+	return nil
+}
+
+// EncodeMaxFlow writes p to the underlying writer.
+// The vertices are converted to 1 indexed.
+func (e *Encoder[T]) EncodeMaxFlow(p *MaxFlowProblem[T]) error {
+	g := p.Graph
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintf(bw, "p max %d %d\n", g.Order(), countArcs(g))
+	fmt.Fprintf(bw, "n %d s\n", p.Source+1)
+	fmt.Fprintf(bw, "n %d t\n", p.Sink+1)
+	for v := range g.Order() {
+		for w, capacity := range g.EdgesFrom(v) {
+			fmt.Fprintf(bw, "a %d %d %s\n", v+1, w+1, e.fmtWeight(capacity))
+		}
+	}
+	return bw.Flush()
+}
+
+// EncodeMinCost writes p to the underlying writer, vertices with
+// zero supply don't have a node line.
+// The vertices are converted to 1 indexed.
+func (e *Encoder[T]) EncodeMinCost(p *MinCostProblem[T]) error {
+	g := p.Graph
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintf(bw, "p min %d %d\n", g.Order(), countArcs(g))
+	zero := e.fmtWeight(*new(T))
+	for v, supply := range p.Supply {
+		if s := e.fmtWeight(supply); s != zero {
+			fmt.Fprintf(bw, "n %d %s\n", v+1, s)
+		}
+	}
+	for v := range g.Order() {
+		for w, a := range g.EdgesFrom(v) {
+			fmt.Fprintf(bw, "a %d %d %s %s %s\n", v+1, w+1,
+				e.fmtWeight(a.Lower), e.fmtWeight(a.Capacity), e.fmtWeight(a.Cost))
+		}
+	}
+	return bw.Flush()
+}
+
+func countArcs[T any](g grafo.Graph[T]) int {
+	m := 0
+	for v := range g.Order() {
+		for range g.EdgesFrom(v) {
+			m++
+		}
+	}
+	return m
+}
@@ -0,0 +1,128 @@
+package gr
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func TestMaxFlowRoundTrip(t *testing.T) {
+	const in = `c Max flow problem.
+p max 4 5
+n 1 s
+n 4 t
+a 1 2 4
+a 1 3 2
+a 2 3 1
+a 2 4 2
+a 3 4 4
+`
+	p, err := NewDecoder(strings.NewReader(in), strconv.Atoi).DecodeMaxFlow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Source != 0 || p.Sink != 3 {
+		t.Errorf("got source %d and sink %d, want 0 and 3", p.Source, p.Sink)
+	}
+	wantGraph := "4 [(0 1):4 (0 2):2 (1 2):1 (1 3):2 (2 3):4]"
+	if got := grafo.String(p.Graph); got != wantGraph {
+		t.Errorf("got graph %s, want %s", got, wantGraph)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, strconv.Itoa).EncodeMaxFlow(p); err != nil {
+		t.Fatal(err)
+	}
+	q, err := NewDecoder(&buf, strconv.Atoi).DecodeMaxFlow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Source != p.Source || q.Sink != p.Sink {
+		t.Errorf("got source %d and sink %d, want %d and %d", q.Source, q.Sink, p.Source, p.Sink)
+	}
+	if got := grafo.String(q.Graph); got != wantGraph {
+		t.Errorf("got graph %s, want %s", got, wantGraph)
+	}
+}
+
+func TestMinCostRoundTrip(t *testing.T) {
+	const in = `p min 3 3
+n 1 5
+n 3 -5
+a 1 2 0 4 1
+a 1 3 0 2 5
+a 2 3 1 4 2
+`
+	p, err := NewDecoder(strings.NewReader(in), strconv.Atoi).DecodeMinCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, strconv.Itoa).EncodeMinCost(p); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(buf.String(), in); diff != "" {
+		t.Errorf("EncodeMinCost() diff: %s", diff)
+	}
+
+	q, err := NewDecoder(&buf, strconv.Atoi).DecodeMinCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(q.Supply, []int{5, 0, -5}); diff != "" {
+		t.Errorf("Supply diff: %s", diff)
+	}
+	var arcs []Arc[int]
+	for w, a := range q.Graph.EdgesFrom(1) {
+		if w != 2 {
+			t.Errorf("got arc 1 -> %d, want 1 -> 2", w)
+		}
+		arcs = append(arcs, a)
+	}
+	if diff := cmp.Diff(arcs, []Arc[int]{{Lower: 1, Capacity: 4, Cost: 2}}); diff != "" {
+		t.Errorf("arcs from 1 diff: %s", diff)
+	}
+}
+
+func TestFlowDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		min      bool
+		wantLine int
+	}{
+		{"missing sink", "p max 2 1\nn 1 s\na 1 2 3\n", false, 3},
+		{"repeated source", "p max 2 1\nn 1 s\nn 2 s\n", false, 3},
+		{"bad designator", "p max 2 1\nn 1 x\n", false, 2},
+		{"sp problem", "p sp 2 1\na 1 2 3\n", false, 1},
+		{"short max arc", "p max 2 1\nn 1 s\nn 2 t\na 1 2\n", false, 4},
+		{"short min arc", "p min 2 1\na 1 2 0 3\n", true, 2},
+		{"min vertex 0", "p min 2 1\nn 0 3\n", true, 2},
+		{"min arcs", "p min 2 2\na 1 2 0 3 1\n", true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), strconv.Atoi)
+			var err error
+			if tt.min {
+				_, err = dec.DecodeMinCost()
+			} else {
+				_, err = dec.DecodeMaxFlow()
+			}
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("got error %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Package gr implements the DIMACS shortest path format (.gr files)
+// used by the 9th DIMACS Implementation Challenge.
+//
+// A .gr file contains a problem line "p sp V E" followed by
+// E arc lines "a v w weight", vertices are 1 indexed. Lines starting
+// with 'c' are comments.
+//
+// The package also supports the DIMACS maximum flow (p max) and
+// minimum cost flow (p min) formats, see MaxFlowProblem and
+// MinCostProblem.
+package gr
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Encoder writes graphs in the DIMACS shortest path format.
+type Encoder[T any] struct {
+	w         io.Writer
+	fmtWeight func(T) string
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+	}
+}
+
+// Encode writes g to the underlying writer.
+// The vertices are converted to 1 indexed.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	n := g.Order()
+	m := countArcs(g)
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintf(bw, "p sp %d %d\n", n, m)
+	for v := range n {
+		for w, wt := range g.EdgesFrom(v) {
+			fmt.Fprintf(bw, "a %d %d %s\n", v+1, w+1, e.fmtWeight(wt))
+		}
+	}
+	return bw.Flush()
+}
+
+// Decoder reads graphs in the DIMACS shortest path format.
+type Decoder[T any] struct {
+	r           *reader
+	parseWeight func(string) (T, error)
+
+	// Values of the problem line.
+	problem  bool
+	vertices int
+	arcs     int
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weights.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           newReader(r),
+		parseWeight: parseWeight,
+	}
+}
+
+// Problem reads the problem line "p sp V E" and returns the number of
+// vertices and arcs it declares. Only comments and empty lines may come
+// before the problem line.
+func (d *Decoder[T]) Problem() (vertices, arcs int, err error) {
+	if d.problem {
+		return d.vertices, d.arcs, nil
+	}
+	fields, err := d.r.problem("sp", 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	d.vertices, d.arcs = fields[0], fields[1]
+	d.problem = true
+	return d.vertices, d.arcs, nil
+}
+
+// Arcs returns an iterator over the arcs of the graph, reading the
+// problem line first if Problem was not called. The arcs are read one
+// by one from the underlying reader and converted to 0 indexed,
+// so huge files can be processed without keeping the graph in memory.
+//
+// If the input is invalid the iterator yields the error and stops,
+// this includes arcs with vertices out of the range [1, V] and a
+// number of arcs different from the declared in the problem line.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Arcs() iter.Seq2[grafo.Edge[T], error] {
+	return func(yield func(grafo.Edge[T], error) bool) {
+		var zero grafo.Edge[T]
+		n, m, err := d.Problem()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		count := 0
+		for {
+			line, err := d.r.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			e, err := d.parseArc(line, n)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if count++; count > m {
+				yield(zero, d.r.errorf("got more than %d arcs declared in the problem line", m))
+				return
+			}
+			if !yield(e, nil) {
+				return
+			}
+		}
+		if count != m {
+			yield(zero, d.r.errorf("got %d arcs, want %d declared in the problem line", count, m))
+		}
+	}
+}
+
+func (d *Decoder[T]) parseArc(line []byte, n int) (grafo.Edge[T], error) {
+	var e grafo.Edge[T]
+	if line[0] != 'a' {
+		return e, d.r.errorf("wrong format: %s", line)
+	}
+	fields := bytes.Fields(line[1:])
+	if len(fields) != 3 {
+		return e, d.r.errorf("got %d elements in one line, want 3", len(fields))
+	}
+
+	v, err := d.r.vertex(fields[0], n)
+	if err != nil {
+		return e, err
+	}
+	w, err := d.r.vertex(fields[1], n)
+	if err != nil {
+		return e, err
+	}
+	weight, err := d.parseWeight(string(fields[2]))
+	if err != nil {
+		return e, d.r.wrap(err)
+	}
+	return grafo.Edge[T]{V: v, W: w, Weight: weight}, nil
+}
+
+// Decode reads a graph from the underlying reader.
+// The vertices are converted to 0 indexed.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	n, _, err := d.Problem()
+	if err != nil {
+		return nil, err
+	}
+	g := multigraph.New[T](n)
+	for e, err := range d.Arcs() {
+		if err != nil {
+			return nil, err
+		}
+		g.Add(e.V, e.W, e.Weight)
+	}
+	return g, nil
+}
+
+// reader reads the lines of DIMACS files, which share
+// the comment and problem line syntax.
+type reader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func newReader(r io.Reader) *reader {
+	return &reader{sc: bufio.NewScanner(r)}
+}
+
+// next returns the next line that is not empty nor a comment,
+// or io.EOF at the end of the input.
+func (r *reader) next() ([]byte, error) {
+	for r.sc.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.sc.Bytes())
+		// Skip empty lines and comments.
+		if len(line) == 0 || line[0] == 'c' {
+			continue
+		}
+		return line, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// problem reads the problem line "p kind n1 n2 ..." and returns
+// its nfields numbers, which can't be negative.
+func (r *reader) problem(kind string, nfields int) ([]int, error) {
+	line, err := r.next()
+	if err == io.EOF {
+		return nil, &encoding.SyntaxError{Line: r.line + 1, Err: errors.New("missing problem line")}
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := bytes.Fields(line)
+	if len(fields) < 2 || string(fields[0]) != "p" {
+		return nil, r.errorf("missing problem line, got: %s", line)
+	}
+	if string(fields[1]) != kind {
+		return nil, r.errorf("got problem %q, want %q", fields[1], kind)
+	}
+	if len(fields) != nfields+2 {
+		return nil, r.errorf("got %d elements in the problem line, want %d", len(fields), nfields+2)
+	}
+	nums := make([]int, nfields)
+	for i, f := range fields[2:] {
+		n, err := strconv.Atoi(string(f))
+		if err != nil {
+			return nil, r.wrap(err)
+		}
+		if n < 0 {
+			return nil, r.errorf("negative number in the problem line: %d", n)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// vertex parses a 1 indexed vertex in the range [1, n]
+// and returns it 0 indexed.
+func (r *reader) vertex(field []byte, n int) (int, error) {
+	v, err := strconv.Atoi(string(field))
+	if err != nil {
+		return 0, r.wrap(err)
+	}
+	if v < 1 || v > n {
+		return 0, r.errorf("vertex %d out of valid range [1, %d]", v, n)
+	}
+	// The DIMACS formats use 1 idexed vertices.
+	// We use 0 indexed.
+	return v - 1, nil
+}
+
+func (r *reader) errorf(format string, args ...any) error {
+	return r.wrap(fmt.Errorf(format, args...))
+}
+
+func (r *reader) wrap(err error) error {
+	return &encoding.SyntaxError{Line: r.line, Err: err}
+}
@@ -0,0 +1,131 @@
+package gr
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func TestDecode(t *testing.T) {
+	const fname = "USA-road-d.NY.gr"
+	f, err := os.Open(filepath.Join("testdata", fname))
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("%s is not available, download it from the 9th DIMACS Implementation Challenge", fname)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g, err := NewDecoder(f, strconv.Atoi).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantV := 264346
+	wantE := 733846
+	if g.Order() != wantV {
+		t.Errorf("got wrong number of vertices: %d, want %d", g.Order(), wantV)
+	}
+
+	edges := 0
+	for v := range g.Order() {
+		for _, _ = range g.EdgesFrom(v) {
+			edges++
+		}
+	}
+	if edges != wantE {
+		t.Errorf("got wrong number of edges: %d, want %d", edges, wantE)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[int](4)
+	g.Add(0, 1, 3)
+	g.Add(1, 2, -4)
+	g.Add(2, 0, 10)
+	g.Add(3, 3, 1)
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, strconv.Itoa).Encode(g); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "p sp 4 4\n") {
+		t.Errorf("got wrong problem line in:\n%s", buf.String())
+	}
+	h, err := NewDecoder(&buf, strconv.Atoi).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(h), grafo.String(g); got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"missing problem line", "c comment\na 1 2 3\n", 2},
+		{"empty", "c comment\n", 2},
+		{"wrong problem", "p max 2 1\na 1 2 3\n", 1},
+		{"negative vertices", "p sp -2 1\n", 1},
+		{"vertex 0", "p sp 2 1\na 0 1 3\n", 2},
+		{"negative vertex", "p sp 2 1\na 1 -1 3\n", 2},
+		{"vertex out of range", "p sp 2 1\na 1 3 3\n", 2},
+		{"more arcs", "p sp 2 1\na 1 2 3\na 2 1 3\n", 3},
+		{"less arcs", "p sp 2 2\nc\na 1 2 3\n", 3},
+		{"bad weight", "p sp 2 1\nc\n\na 1 2 x\n", 4},
+		{"repeated problem line", "p sp 2 1\np sp 2 1\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.in), strconv.Atoi).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestArcs(t *testing.T) {
+	in := "c header\np sp 3 3\na 1 2 5\na 2 3 6\na 3 1 7\n"
+	dec := NewDecoder(strings.NewReader(in), strconv.Atoi)
+	n, m, err := dec.Problem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || m != 3 {
+		t.Errorf("Problem() = %d, %d, want 3, 3", n, m)
+	}
+
+	var got []grafo.Edge[int]
+	for e, err := range dec.Arcs() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+		if len(got) == 2 {
+			break
+		}
+	}
+	want := []grafo.Edge[int]{{V: 0, W: 1, Weight: 5}, {V: 1, W: 2, Weight: 6}}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Arcs() diff: %s", diff)
+	}
+}
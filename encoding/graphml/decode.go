@@ -0,0 +1,390 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Decoder reads graphs in GraphML.
+//
+// Node IDs are mapped to vertices 0, 1, ..., n-1 in the order they
+// first appear, see Names. Nested graphs are flattened into a single
+// graph, hyperedges and ports are ignored. Undirected edges v - w
+// are decoded as the edges v -> w and w -> v.
+type Decoder[T any] struct {
+	r           io.Reader
+	parseWeight func(string) (T, error)
+	weightKey   string
+
+	keys     []Key
+	names    []string
+	nodeData []map[string]any
+	edgeData []EdgeData
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weight of the edges.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+		weightKey:   "weight",
+	}
+}
+
+// SetWeightKey sets the key that holds the weight of the edges, it
+// matches either the key id or its attr.name, the default is "weight".
+// Edges without the data use the key default or the zero value of T.
+func (d *Decoder[T]) SetWeightKey(key string) {
+	d.weightKey = key
+}
+
+// Keys returns the keys declared in the last decoded graph.
+func (d *Decoder[T]) Keys() []Key { return d.keys }
+
+// Names returns the node IDs of the last decoded graph,
+// Names()[v] is the ID of vertex v.
+func (d *Decoder[T]) Names() []string { return d.names }
+
+// NodeData returns the data of the nodes of the last decoded graph,
+// NodeData()[v] holds the data of v indexed by the attr.name of
+// the key, or its id if there is no name.
+//
+// The values are typed by the key attr.type: boolean as bool,
+// int and long as int64, float and double as float64 and string
+// as string. Keys with a default value are set in every node.
+func (d *Decoder[T]) NodeData() []map[string]any { return d.nodeData }
+
+// EdgeData returns the data of the edges of the last decoded graph,
+// in the order they appear in the input. The values are typed as
+// in NodeData.
+func (d *Decoder[T]) EdgeData() []EdgeData { return d.edgeData }
+
+// Decode reads a graph from the underlying reader.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	p := &parser[T]{
+		dec:         xml.NewDecoder(d.r),
+		parseWeight: d.parseWeight,
+		weightKey:   d.weightKey,
+		keys:        make(map[string]Key),
+		ids:         make(map[string]int),
+	}
+	if err := p.parse(); err != nil {
+		var xerr *xml.SyntaxError
+		if errors.As(err, &xerr) {
+			return nil, &encoding.SyntaxError{Line: xerr.Line, Err: errors.New(xerr.Msg)}
+		}
+		return nil, err
+	}
+
+	g := multigraph.New[T](len(p.names))
+	for _, e := range p.edges {
+		if e.directed || e.v == e.w {
+			g.Add(e.v, e.w, e.weight)
+		} else {
+			g.AddBoth(e.v, e.w, e.weight)
+		}
+	}
+
+	d.keys = p.keyList
+	d.names = p.names
+	d.nodeData = p.nodeData
+	d.edgeData = make([]EdgeData, len(p.edges))
+	for i, e := range p.edges {
+		d.edgeData[i] = EdgeData{V: e.v, W: e.w, Data: e.data}
+	}
+	return g, nil
+}
+
+type parser[T any] struct {
+	dec         *xml.Decoder
+	parseWeight func(string) (T, error)
+	weightKey   string
+
+	keys     map[string]Key // Indexed by id.
+	keyList  []Key
+	ids      map[string]int
+	names    []string
+	nodeData []map[string]any
+	edges    []decEdge[T]
+}
+
+type decEdge[T any] struct {
+	v, w     int
+	directed bool
+	weight   T
+	data     map[string]any
+}
+
+func (p *parser[T]) errorf(format string, args ...any) error {
+	line, _ := p.dec.InputPos()
+	return &encoding.SyntaxError{Line: line, Err: fmt.Errorf(format, args...)}
+}
+
+func (p *parser[T]) parse() error {
+	// directed holds the edgedefault of the enclosing graphs.
+	var directed []bool
+	// nodes holds the vertex of the enclosing node of each graph level,
+	// or -1. They are indices as the nested graphs add vertices, which
+	// may move p.nodeData.
+	var nodes []int
+	var edge *decEdge[T]
+	var weight *string
+
+	for {
+		tok, err := p.dec.Token()
+		if err == io.EOF {
+			if len(directed) > 0 {
+				return p.errorf("unexpected EOF")
+			}
+			return p.errorf("missing graph element")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "key":
+				if err := p.parseKey(t); err != nil {
+					return err
+				}
+			case "graph":
+				switch attr(t, "edgedefault") {
+				case "directed", "":
+					directed = append(directed, true)
+				case "undirected":
+					directed = append(directed, false)
+				default:
+					return p.errorf("invalid edgedefault %q", attr(t, "edgedefault"))
+				}
+				nodes = append(nodes, -1)
+			case "node":
+				if len(directed) == 0 {
+					return p.errorf("node outside of a graph")
+				}
+				id := attr(t, "id")
+				if id == "" {
+					return p.errorf("node without id")
+				}
+				nodes[len(nodes)-1] = p.vertex(id)
+			case "edge":
+				if len(directed) == 0 {
+					return p.errorf("edge outside of a graph")
+				}
+				e, err := p.startEdge(t, directed[len(directed)-1])
+				if err != nil {
+					return err
+				}
+				edge, weight = e, nil
+			case "data":
+				var data struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				}
+				if err := p.dec.DecodeElement(&data, &t); err != nil {
+					return err
+				}
+				switch {
+				case edge != nil:
+					k := p.keys[data.Key]
+					if data.Key == p.weightKey || k.Name == p.weightKey {
+						weight = &data.Value
+					}
+					if err := p.setData(&edge.data, data.Key, data.Value); err != nil {
+						return err
+					}
+				case len(nodes) > 0 && nodes[len(nodes)-1] != -1:
+					node := nodes[len(nodes)-1]
+					if err := p.setData(&p.nodeData[node], data.Key, data.Value); err != nil {
+						return err
+					}
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "graph":
+				directed = directed[:len(directed)-1]
+				nodes = nodes[:len(nodes)-1]
+				if len(directed) == 0 {
+					// Only the first top level graph is decoded.
+					return nil
+				}
+			case "node":
+				if len(nodes) > 0 {
+					nodes[len(nodes)-1] = -1
+				}
+			case "edge":
+				if err := p.endEdge(edge, weight); err != nil {
+					return err
+				}
+				edge = nil
+			}
+		}
+	}
+}
+
+func (p *parser[T]) parseKey(t xml.StartElement) error {
+	var key struct {
+		ID      string `xml:"id,attr"`
+		For     string `xml:"for,attr"`
+		Name    string `xml:"attr.name,attr"`
+		Type    string `xml:"attr.type,attr"`
+		Default *struct {
+			Value string `xml:",chardata"`
+		} `xml:"default"`
+	}
+	if err := p.dec.DecodeElement(&key, &t); err != nil {
+		return err
+	}
+	if key.ID == "" {
+		return p.errorf("key without id")
+	}
+	k := Key{ID: key.ID, For: key.For, Name: key.Name, Type: key.Type}
+	if k.For == "" {
+		k.For = "all"
+	}
+	if k.Type == "" {
+		k.Type = "string"
+	}
+	switch k.Type {
+	case "boolean", "int", "long", "float", "double", "string":
+	default:
+		return p.errorf("key %q: unknown type %q", k.ID, k.Type)
+	}
+	if key.Default != nil {
+		k.Default = strings.TrimSpace(key.Default.Value)
+		if _, err := convert(k.Type, k.Default); err != nil {
+			return p.errorf("key %q default: %v", k.ID, err)
+		}
+	}
+	p.keys[k.ID] = k
+	p.keyList = append(p.keyList, k)
+	return nil
+}
+
+func (p *parser[T]) startEdge(t xml.StartElement, directed bool) (*decEdge[T], error) {
+	source, target := attr(t, "source"), attr(t, "target")
+	if source == "" || target == "" {
+		return nil, p.errorf("edge without source or target")
+	}
+	switch attr(t, "directed") {
+	case "":
+	case "true":
+		directed = true
+	case "false":
+		directed = false
+	default:
+		return nil, p.errorf("invalid directed attribute %q", attr(t, "directed"))
+	}
+	return &decEdge[T]{v: p.vertex(source), w: p.vertex(target), directed: directed}, nil
+}
+
+func (p *parser[T]) endEdge(e *decEdge[T], weight *string) error {
+	if weight == nil {
+		for _, k := range p.keyList {
+			if (k.ID == p.weightKey || k.Name == p.weightKey) && k.Default != "" {
+				weight = &k.Default
+			}
+		}
+	}
+	if weight != nil {
+		wt, err := p.parseWeight(strings.TrimSpace(*weight))
+		if err != nil {
+			return p.errorf("weight: %w", err)
+		}
+		e.weight = wt
+	}
+	p.setDefaults(&e.data, "edge")
+	p.edges = append(p.edges, *e)
+	return nil
+}
+
+func (p *parser[T]) vertex(id string) int {
+	v, ok := p.ids[id]
+	if !ok {
+		v = len(p.names)
+		p.ids[id] = v
+		p.names = append(p.names, id)
+		var data map[string]any
+		p.setDefaults(&data, "node")
+		p.nodeData = append(p.nodeData, data)
+	}
+	return v
+}
+
+func (p *parser[T]) setData(data *map[string]any, key, value string) error {
+	k, ok := p.keys[key]
+	if !ok {
+		return p.errorf("undeclared key %q", key)
+	}
+	val, err := convert(k.Type, strings.TrimSpace(value))
+	if err != nil {
+		return p.errorf("key %q: %v", key, err)
+	}
+	if *data == nil {
+		*data = make(map[string]any)
+	}
+	(*data)[keyName(k)] = val
+	return nil
+}
+
+// setDefaults sets the default values of the keys for the kind
+// of element in data.
+func (p *parser[T]) setDefaults(data *map[string]any, kind string) {
+	for _, k := range p.keyList {
+		if k.Default == "" || (k.For != kind && k.For != "all") {
+			continue
+		}
+		if _, ok := (*data)[keyName(k)]; ok {
+			continue
+		}
+		if *data == nil {
+			*data = make(map[string]any)
+		}
+		// The default was validated when the key was parsed.
+		(*data)[keyName(k)], _ = convert(k.Type, k.Default)
+	}
+}
+
+func keyName(k Key) string {
+	if k.Name != "" {
+		return k.Name
+	}
+	return k.ID
+}
+
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// convert converts s to the Go type of the GraphML type typ.
+func convert(typ, s string) (any, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(s)
+	case "int", "long":
+		return strconv.ParseInt(s, 10, 64)
+	case "float", "double":
+		return strconv.ParseFloat(s, 64)
+	case "string":
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
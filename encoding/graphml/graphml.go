@@ -0,0 +1,282 @@
+// Package graphml implements an encoder and a decoder for GraphML,
+// the XML format used by tools like Gephi, yEd and NetworkX.
+//
+// See http://graphml.graphdrawing.org/specification.html.
+package graphml
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+)
+
+// Key declares a GraphML attribute.
+type Key struct {
+	ID      string // Identifier used by the data elements.
+	For     string // Kind of element: "node", "edge", "graph" or "all".
+	Name    string // The attr.name, the name of the attribute.
+	Type    string // The attr.type: boolean, int, long, float, double or string.
+	Default string // The default value, if any.
+}
+
+// EdgeData holds the data of the edge V -> W.
+type EdgeData struct {
+	V, W int
+	Data map[string]any
+}
+
+// Encoder writes graphs in GraphML.
+type Encoder[T any] struct {
+	w          io.Writer
+	fmtWeight  func(T) string
+	weightKey  string
+	undirected bool
+	vertexData func(v int) map[string]any
+	edgeData   func(v, w int, weight T) map[string]any
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights. If fmtWeight is nil the
+// weights are not written.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+		weightKey: "weight",
+	}
+}
+
+// SetWeightKey sets the attr.name of the key that holds the
+// weight of the edges, the default is "weight".
+func (e *Encoder[T]) SetWeightKey(name string) {
+	e.weightKey = name
+}
+
+// SetUndirected makes the encoder write an undirected graph.
+// Each pair of edges v -> w and w -> v with the same data
+// is merged into a single undirected edge, the remaining edges are
+// written as they are.
+func (e *Encoder[T]) SetUndirected(undirected bool) {
+	e.undirected = undirected
+}
+
+// SetVertexData sets a function that returns the data of each vertex.
+// The values must be of type bool, string or numeric, the type
+// of the keys is inferred from them.
+func (e *Encoder[T]) SetVertexData(data func(v int) map[string]any) {
+	e.vertexData = data
+}
+
+// SetEdgeData sets a function that returns the data of each edge.
+// The values must be of type bool, string or numeric, the type
+// of the keys is inferred from them.
+func (e *Encoder[T]) SetEdgeData(data func(v, w int, weight T) map[string]any) {
+	e.edgeData = data
+}
+
+type encEdge struct {
+	v, w   int
+	weight string
+	data   map[string]any
+}
+
+// Encode writes g to the underlying writer. The vertex v is written
+// as a node with ID "nv".
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	n := g.Order()
+	nodes := make([]map[string]any, n)
+	var edges []encEdge
+	for v := range n {
+		if e.vertexData != nil {
+			nodes[v] = e.vertexData(v)
+		}
+		for w, wt := range g.EdgesFrom(v) {
+			ed := encEdge{v: v, w: w}
+			if e.fmtWeight != nil {
+				ed.weight = e.fmtWeight(wt)
+			}
+			if e.edgeData != nil {
+				ed.data = e.edgeData(v, w, wt)
+			}
+			edges = append(edges, ed)
+		}
+	}
+	if e.undirected {
+		edges = mergeSymmetric(edges)
+	}
+
+	nodeKeys, err := inferKeys("node", "v", nodes)
+	if err != nil {
+		return err
+	}
+	edgeMaps := make([]map[string]any, len(edges))
+	for i, ed := range edges {
+		edgeMaps[i] = ed.data
+	}
+	edgeKeys, err := inferKeys("edge", "e", edgeMaps)
+	if err != nil {
+		return err
+	}
+	var weightKey *Key
+	if e.fmtWeight != nil {
+		if _, ok := edgeKeys[e.weightKey]; ok {
+			return fmt.Errorf("edge data uses the weight key %q", e.weightKey)
+		}
+		// The weights of other types are formatted as strings.
+		typ := cmp.Or(typeOf(reflect.TypeFor[T]()), "string")
+		weightKey = &Key{ID: "weight", For: "edge", Name: e.weightKey, Type: typ}
+	}
+
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintln(bw, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(bw, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	if weightKey != nil {
+		writeKey(bw, *weightKey)
+	}
+	for _, name := range slices.Sorted(maps.Keys(nodeKeys)) {
+		writeKey(bw, nodeKeys[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(edgeKeys)) {
+		writeKey(bw, edgeKeys[name])
+	}
+
+	edgeDefault := "directed"
+	if e.undirected {
+		edgeDefault = "undirected"
+	}
+	fmt.Fprintf(bw, "  <graph id=\"G\" edgedefault=\"%s\">\n", edgeDefault)
+	for v, data := range nodes {
+		if len(data) == 0 {
+			fmt.Fprintf(bw, "    <node id=\"n%d\"/>\n", v)
+			continue
+		}
+		fmt.Fprintf(bw, "    <node id=\"n%d\">\n", v)
+		writeData(bw, nodeKeys, data)
+		fmt.Fprintln(bw, "    </node>")
+	}
+	for _, ed := range edges {
+		if weightKey == nil && len(ed.data) == 0 {
+			fmt.Fprintf(bw, "    <edge source=\"n%d\" target=\"n%d\"/>\n", ed.v, ed.w)
+			continue
+		}
+		fmt.Fprintf(bw, "    <edge source=\"n%d\" target=\"n%d\">\n", ed.v, ed.w)
+		if weightKey != nil {
+			fmt.Fprintf(bw, "      <data key=\"%s\">%s</data>\n", weightKey.ID, escape(ed.weight))
+		}
+		writeData(bw, edgeKeys, ed.data)
+		fmt.Fprintln(bw, "    </edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+// mergeSymmetric removes from edges one edge of each pair
+// v -> w and w -> v with equal weight and data.
+func mergeSymmetric(edges []encEdge) []encEdge {
+	type key struct {
+		v, w         int
+		weight, data string
+	}
+	keyOf := func(ed encEdge) key {
+		return key{ed.v, ed.w, ed.weight, fmt.Sprint(ed.data)}
+	}
+	count := make(map[key]int)
+	for _, ed := range edges {
+		count[keyOf(ed)]++
+	}
+	merged := make(map[key]int)
+	res := edges[:0]
+	for _, ed := range edges {
+		k := keyOf(ed)
+		if merged[k] > 0 {
+			merged[k]--
+			continue
+		}
+		count[k]--
+		back := key{k.w, k.v, k.weight, k.data}
+		if k.v != k.w && count[back] > 0 {
+			count[back]--
+			merged[back]++
+		}
+		res = append(res, ed)
+	}
+	return res
+}
+
+// inferKeys returns the keys, indexed by name, used by the maps in data.
+// The IDs of the keys have the given prefix.
+func inferKeys(kind, prefix string, data []map[string]any) (map[string]Key, error) {
+	keys := make(map[string]Key)
+	for _, m := range data {
+		for name, val := range m {
+			typ := typeOf(reflect.TypeOf(val))
+			if typ == "" {
+				return nil, fmt.Errorf("unsupported type %T of %s data %q", val, kind, name)
+			}
+			k, ok := keys[name]
+			if ok && k.Type != typ {
+				return nil, fmt.Errorf("%s data %q has types %s and %s", kind, name, k.Type, typ)
+			}
+			keys[name] = Key{For: kind, Name: name, Type: typ}
+		}
+	}
+	for i, name := range slices.Sorted(maps.Keys(keys)) {
+		k := keys[name]
+		k.ID = prefix + strconv.Itoa(i)
+		keys[name] = k
+	}
+	return keys, nil
+}
+
+// typeOf returns the GraphML type of t, or "" if there is none.
+func typeOf(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		return "string"
+	}
+	return ""
+}
+
+func writeKey(w io.Writer, k Key) {
+	fmt.Fprintf(w, "  <key id=\"%s\" for=\"%s\" attr.name=\"%s\" attr.type=\"%s\"/>\n",
+		escape(k.ID), k.For, escape(k.Name), k.Type)
+}
+
+func writeData(w io.Writer, keys map[string]Key, data map[string]any) {
+	for _, name := range slices.Sorted(maps.Keys(data)) {
+		fmt.Fprintf(w, "      <data key=\"%s\">%s</data>\n", escape(keys[name].ID), escape(fmt.Sprint(data[name])))
+	}
+}
+
+var escaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&quot;",
+	`'`, "&apos;",
+)
+
+func escape(s string) string { return escaper.Replace(s) }
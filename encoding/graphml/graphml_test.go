@@ -0,0 +1,204 @@
+package graphml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="color" attr.type="string">
+    <default>yellow</default>
+  </key>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d2" for="edge" attr.name="active" attr.type="boolean">
+    <default>true</default>
+  </key>
+  <key id="d3" for="node" attr.name="size" attr.type="int"/>
+  <graph id="G" edgedefault="undirected">
+    <node id="a">
+      <data key="d0">green</data>
+      <data key="d3">3</data>
+    </node>
+    <node id="b"/>
+    <node id="c"/>
+    <edge source="a" target="b">
+      <data key="d1">1.5</data>
+    </edge>
+    <edge source="a" target="b">
+      <data key="d1">2.5</data>
+      <data key="d2">false</data>
+    </edge>
+    <edge source="b" target="c" directed="true">
+      <data key="d1">4</data>
+    </edge>
+    <edge source="c" target="c"/>
+  </graph>
+</graphml>
+`
+
+func parseFloat(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+
+func TestDecode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(sample), parseFloat)
+	g, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantGraph := "3 [{0 1}:1.5 {0 1}:2.5 (1 2):4 (2 2)]"
+	if got := grafo.String(g); got != wantGraph {
+		t.Errorf("got graph %s, want %s", got, wantGraph)
+	}
+	if diff := cmp.Diff(dec.Names(), []string{"a", "b", "c"}); diff != "" {
+		t.Errorf("Names() diff: %s", diff)
+	}
+	wantNodes := []map[string]any{
+		{"color": "green", "size": int64(3)},
+		{"color": "yellow"},
+		{"color": "yellow"},
+	}
+	if diff := cmp.Diff(dec.NodeData(), wantNodes); diff != "" {
+		t.Errorf("NodeData() diff: %s", diff)
+	}
+	wantEdges := []EdgeData{
+		{V: 0, W: 1, Data: map[string]any{"weight": 1.5, "active": true}},
+		{V: 0, W: 1, Data: map[string]any{"weight": 2.5, "active": false}},
+		{V: 1, W: 2, Data: map[string]any{"weight": 4.0, "active": true}},
+		{V: 2, W: 2, Data: map[string]any{"active": true}},
+	}
+	if diff := cmp.Diff(dec.EdgeData(), wantEdges); diff != "" {
+		t.Errorf("EdgeData() diff: %s", diff)
+	}
+}
+
+func TestDecodeWeightKey(t *testing.T) {
+	in := `<graphml><key id="cap" for="edge" attr.type="long"><default>7</default></key>
+<graph edgedefault="directed">
+<edge source="x" target="y"><data key="cap">3</data></edge>
+<edge source="y" target="x"/>
+</graph></graphml>`
+	dec := NewDecoder(strings.NewReader(in), strconv.Atoi)
+	dec.SetWeightKey("cap")
+	g, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(g), "2 [(0 1):3 (1 0):7]"; got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+}
+
+func TestDecodeNestedGraph(t *testing.T) {
+	// The nested graph adds vertices before the data of a, and its
+	// nodes must not hide a from the data after it.
+	in := `<graphml><key id="d0" for="node" attr.name="color" attr.type="string"/>
+<graph edgedefault="directed">
+<node id="a"><graph><node id="x"><data key="d0">blue</data></node>
+<edge source="x" target="y"/><edge source="y" target="z"/></graph>
+<data key="d0">red</data></node>
+</graph></graphml>`
+	dec := NewDecoder(strings.NewReader(in), parseFloat)
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{{"color": "red"}, {"color": "blue"}, nil, nil}
+	if diff := cmp.Diff(dec.NodeData(), want); diff != "" {
+		t.Errorf("NodeData() diff: %s", diff)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"bad xml", "<graphml>\n<graph>\n<node id=\"a\">\n</graph>", 4},
+		{"undeclared key", "<graphml>\n<graph>\n<node id=\"a\"><data key=\"x\">1</data></node>\n</graph></graphml>", 3},
+		{"bad typed value", "<graphml><key id=\"k\" attr.type=\"int\"/>\n<graph>\n<node id=\"a\"><data key=\"k\">x</data></node></graph></graphml>", 3},
+		{"bad weight", "<graphml><key id=\"weight\" attr.type=\"string\"/>\n<graph>\n\n<edge source=\"a\" target=\"b\"><data key=\"weight\">x</data></edge></graph></graphml>", 4},
+		{"missing graph", "<graphml>\n</graphml>\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.in), strconv.Atoi).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[float64](4)
+	g.AddBoth(0, 1, 1.5)
+	g.Add(1, 2, 3)
+	g.Add(3, 3, 1)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) })
+	enc.SetUndirected(true)
+	enc.SetVertexData(func(v int) map[string]any {
+		if v == 0 {
+			return map[string]any{"label": "<first>", "rank": 1}
+		}
+		return nil
+	})
+	enc.SetEdgeData(func(v, w int, weight float64) map[string]any {
+		return map[string]any{"heavy": weight > 2}
+	})
+	if err := enc.Encode(grafo.Sort(g)); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "<edge "); got != 3 {
+		t.Errorf("got %d edges written, want 3:\n%s", got, buf.String())
+	}
+
+	dec := NewDecoder(&buf, parseFloat)
+	h, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The edge 1 -> 2 is written as undirected.
+	want := "4 [{0 1}:1.5 {1 2}:3 (3 3):1]"
+	if got := grafo.String(h); got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+	wantNodes := []map[string]any{{"label": "<first>", "rank": int64(1)}, nil, nil, nil}
+	if diff := cmp.Diff(dec.NodeData(), wantNodes); diff != "" {
+		t.Errorf("NodeData() diff: %s", diff)
+	}
+	if got := dec.EdgeData()[1].Data["heavy"]; got != true {
+		t.Errorf("got heavy = %v for edge 1 - 2, want true", got)
+	}
+}
+
+func TestEncodeStringWeight(t *testing.T) {
+	type span struct{ lo, hi int }
+	g := grafo.NewMutable[span](2)
+	g.Add(0, 1, span{1, 3})
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, func(s span) string { return fmt.Sprintf("%d-%d", s.lo, s.hi) })
+	if err := enc.Encode(g); err != nil {
+		t.Fatal(err)
+	}
+	// The weights without a GraphML type are strings.
+	want := `<key id="weight" for="edge" attr.name="weight" attr.type="string"/>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got:\n%s\nwant the key %s", buf.String(), want)
+	}
+}
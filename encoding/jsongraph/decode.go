@@ -0,0 +1,385 @@
+package jsongraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Decoder reads graphs in JSON. The format, JSON Graph Format
+// (version 1 or 2) or node-link, is detected from the input.
+//
+// The input is read as a stream, one node or edge at a time, so
+// only the decoded graph is kept in memory. Node IDs are mapped to
+// vertices 0, 1, ..., n-1 in the order they first appear, see Names.
+// Undirected edges v - w are decoded as the edges v -> w and w -> v.
+type Decoder[T any] struct {
+	r         io.Reader
+	weightKey string
+
+	directed bool
+	metadata map[string]any
+	names    []string
+	nodeData []map[string]any
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder[T any](r io.Reader) *Decoder[T] {
+	return &Decoder[T]{
+		r:         r,
+		weightKey: "weight",
+	}
+}
+
+// SetWeightKey sets the edge attribute that holds the weight, the
+// default is "weight". In JGF the weight is read from the edge
+// metadata. Edges without the weight have the zero value of T.
+func (d *Decoder[T]) SetWeightKey(key string) {
+	d.weightKey = key
+}
+
+// Directed tells if the last decoded graph is directed.
+func (d *Decoder[T]) Directed() bool { return d.directed }
+
+// Metadata returns the metadata of the last decoded graph.
+func (d *Decoder[T]) Metadata() map[string]any { return d.metadata }
+
+// Names returns the node IDs of the last decoded graph,
+// Names()[v] is the ID of vertex v. Numeric IDs are returned
+// as they appear in the input.
+func (d *Decoder[T]) Names() []string { return d.names }
+
+// NodeData returns the data of the nodes of the last decoded graph.
+// In JGF it holds the node label and metadata, in node-link
+// the node attributes but the id.
+func (d *Decoder[T]) NodeData() []map[string]any { return d.nodeData }
+
+// Decode reads a graph from the underlying reader, if the input
+// holds many graphs (JGF "graphs") only the first is decoded.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	lines := &lineCounter{r: d.r}
+	p := &parser[T]{
+		dec:       json.NewDecoder(lines),
+		lines:     lines,
+		weightKey: d.weightKey,
+		directed:  true,
+		ids:       make(map[string]int),
+		metadata:  make(map[string]any),
+	}
+	if err := p.parseObject(p.topLevel); err != nil {
+		return nil, p.syntaxError(err)
+	}
+
+	g := multigraph.New[T](len(p.names))
+	for _, e := range p.edges {
+		directed := p.directed
+		if e.directed != nil {
+			directed = *e.directed
+		}
+		if directed || e.v == e.w {
+			g.Add(e.v, e.w, e.weight)
+		} else {
+			g.AddBoth(e.v, e.w, e.weight)
+		}
+	}
+
+	d.directed = p.directed
+	d.metadata = p.metadata
+	d.names = p.names
+	d.nodeData = p.nodeData
+	return g, nil
+}
+
+type parser[T any] struct {
+	dec       *json.Decoder
+	lines     *lineCounter
+	weightKey string
+
+	// jgf is true if the nodes are inside a graph object.
+	jgf      bool
+	directed bool
+	metadata map[string]any
+	ids      map[string]int
+	names    []string
+	nodeData []map[string]any
+	edges    []edge[T]
+}
+
+type edge[T any] struct {
+	v, w     int
+	directed *bool
+	weight   T
+}
+
+// syntaxError converts err to an *encoding.SyntaxError.
+func (p *parser[T]) syntaxError(err error) error {
+	var eerr *encoding.SyntaxError
+	if errors.As(err, &eerr) {
+		return err
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	offset := p.dec.InputOffset()
+	var serr *json.SyntaxError
+	var terr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &serr):
+		offset = serr.Offset
+	case errors.As(err, &terr):
+		offset = terr.Offset
+	}
+	return &encoding.SyntaxError{Line: p.lines.line(offset), Err: err}
+}
+
+// wrap reports err at the end of the last element read, it is used for
+// the errors found after an element is decoded.
+func (p *parser[T]) wrap(err error) error {
+	return &encoding.SyntaxError{Line: p.lines.line(p.dec.InputOffset()), Err: err}
+}
+
+// parseObject parses an object calling field for each of its keys,
+// field must consume the value of the key.
+func (p *parser[T]) parseObject(field func(key string) error) error {
+	if err := p.expect(json.Delim('{')); err != nil {
+		return err
+	}
+	for p.dec.More() {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := field(tok.(string)); err != nil {
+			return err
+		}
+	}
+	return p.expect(json.Delim('}'))
+}
+
+func (p *parser[T]) expect(delim json.Delim) error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("got %v, want %v", tok, delim)
+	}
+	return nil
+}
+
+func (p *parser[T]) skip() error {
+	var v json.RawMessage
+	return p.dec.Decode(&v)
+}
+
+func (p *parser[T]) topLevel(key string) error {
+	switch key {
+	case "graph":
+		return p.parseObject(p.graph)
+	case "graphs":
+		if err := p.expect(json.Delim('[')); err != nil {
+			return err
+		}
+		if p.dec.More() {
+			if err := p.parseObject(p.graph); err != nil {
+				return err
+			}
+		}
+		for p.dec.More() {
+			if err := p.skip(); err != nil {
+				return err
+			}
+		}
+		return p.expect(json.Delim(']'))
+	case "directed":
+		return p.dec.Decode(&p.directed)
+	case "nodes":
+		return p.parseNodes()
+	case "links", "edges":
+		return p.parseEdges()
+	}
+	return p.skip()
+}
+
+// graph parses the keys of a JGF graph or of the node-link
+// graph attributes.
+func (p *parser[T]) graph(key string) error {
+	switch key {
+	case "directed":
+		return p.dec.Decode(&p.directed)
+	case "nodes":
+		p.jgf = true
+		return p.parseNodes()
+	case "edges":
+		p.jgf = true
+		return p.parseEdges()
+	case "metadata":
+		var m map[string]any
+		if err := p.dec.Decode(&m); err != nil {
+			return err
+		}
+		maps.Copy(p.metadata, m)
+		return nil
+	case "id", "label", "type", "hyperedges":
+		return p.skip()
+	}
+	var v any
+	if err := p.dec.Decode(&v); err != nil {
+		return err
+	}
+	p.metadata[key] = v
+	return nil
+}
+
+// parseNodes parses an array of nodes or, in JGF version 2,
+// an object mapping IDs to nodes.
+func (p *parser[T]) parseNodes() error {
+	tok, err := p.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('['):
+		for p.dec.More() {
+			var node map[string]json.RawMessage
+			if err := p.dec.Decode(&node); err != nil {
+				return err
+			}
+			id, ok := node["id"]
+			if !ok {
+				return p.wrap(errors.New("node without id"))
+			}
+			v, err := p.vertex(id)
+			if err != nil {
+				return err
+			}
+			delete(node, "id")
+			if err := p.setNodeData(v, node); err != nil {
+				return err
+			}
+		}
+		return p.expect(json.Delim(']'))
+
+	case json.Delim('{'):
+		for p.dec.More() {
+			tok, err := p.dec.Token()
+			if err != nil {
+				return err
+			}
+			v := p.vertexName(tok.(string))
+			var node map[string]json.RawMessage
+			if err := p.dec.Decode(&node); err != nil {
+				return err
+			}
+			if err := p.setNodeData(v, node); err != nil {
+				return err
+			}
+		}
+		return p.expect(json.Delim('}'))
+	}
+	return fmt.Errorf("got %v, want nodes array or object", tok)
+}
+
+func (p *parser[T]) setNodeData(v int, node map[string]json.RawMessage) error {
+	if len(node) == 0 {
+		return nil
+	}
+	data := make(map[string]any, len(node))
+	for k, raw := range node {
+		if p.jgf && k == "metadata" {
+			var m map[string]any
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return p.wrap(fmt.Errorf("node metadata: %w", err))
+			}
+			maps.Copy(data, m)
+			continue
+		}
+		var val any
+		if err := json.Unmarshal(raw, &val); err != nil {
+			return err
+		}
+		data[k] = val
+	}
+	p.nodeData[v] = data
+	return nil
+}
+
+func (p *parser[T]) parseEdges() error {
+	if err := p.expect(json.Delim('[')); err != nil {
+		return err
+	}
+	for p.dec.More() {
+		var fields map[string]json.RawMessage
+		if err := p.dec.Decode(&fields); err != nil {
+			return err
+		}
+		source, ok1 := fields["source"]
+		target, ok2 := fields["target"]
+		if !ok1 || !ok2 {
+			return p.wrap(errors.New("edge without source or target"))
+		}
+		v, err := p.vertex(source)
+		if err != nil {
+			return err
+		}
+		w, err := p.vertex(target)
+		if err != nil {
+			return err
+		}
+		e := edge[T]{v: v, w: w}
+
+		if raw, ok := fields["directed"]; ok {
+			e.directed = new(bool)
+			if err := json.Unmarshal(raw, e.directed); err != nil {
+				return p.wrap(fmt.Errorf("directed: %w", err))
+			}
+		}
+		weights := fields
+		if p.jgf {
+			weights = nil
+			if raw, ok := fields["metadata"]; ok {
+				if err := json.Unmarshal(raw, &weights); err != nil {
+					return p.wrap(fmt.Errorf("edge metadata: %w", err))
+				}
+			}
+		}
+		if raw, ok := weights[p.weightKey]; ok {
+			if err := json.Unmarshal(raw, &e.weight); err != nil {
+				return p.wrap(fmt.Errorf("weight: %w", err))
+			}
+		}
+		p.edges = append(p.edges, e)
+	}
+	return p.expect(json.Delim(']'))
+}
+
+// vertex returns the vertex of the JSON encoded id.
+func (p *parser[T]) vertex(id json.RawMessage) (int, error) {
+	var name string
+	if len(id) > 0 && id[0] == '"' {
+		if err := json.Unmarshal(id, &name); err != nil {
+			return 0, p.wrap(err)
+		}
+	} else {
+		name = string(id)
+	}
+	return p.vertexName(name), nil
+}
+
+func (p *parser[T]) vertexName(name string) int {
+	v, ok := p.ids[name]
+	if !ok {
+		v = len(p.names)
+		p.ids[name] = v
+		p.names = append(p.names, name)
+		p.nodeData = append(p.nodeData, nil)
+	}
+	return v
+}
@@ -0,0 +1,295 @@
+// Package jsongraph implements an encoder and a decoder for graphs
+// in JSON, using either the JSON Graph Format or the NetworkX
+// node-link format.
+//
+// The weights are converted with encoding/json, so any weight type
+// that can be marshaled to JSON is supported, including types that
+// implement json.Marshaler and json.Unmarshaler.
+//
+// A JSON Graph Format (https://jsongraphformat.info) document
+// looks like:
+//
+//	{"graph": {
+//		"directed": true,
+//		"metadata": {"name": "example"},
+//		"nodes": {"0": {"label": "a"}, "1": {}},
+//		"edges": [{"source": "0", "target": "1", "metadata": {"weight": 3}}]
+//	}}
+//
+// And a node-link document, as written by NetworkX node_link_data,
+// looks like:
+//
+//	{
+//		"directed": true,
+//		"multigraph": true,
+//		"graph": {"name": "example"},
+//		"nodes": [{"id": 0, "label": "a"}, {"id": 1}],
+//		"links": [{"source": 0, "target": 1, "weight": 3}]
+//	}
+package jsongraph
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"maps"
+	"sort"
+	"strconv"
+
+	"github.com/rschio/grafo"
+)
+
+// Format is a JSON representation of graphs.
+type Format int
+
+const (
+	// JGF is the JSON Graph Format version 2.
+	JGF Format = iota
+	// NodeLink is the NetworkX node-link format.
+	NodeLink
+)
+
+// Encoder writes graphs in JSON.
+type Encoder[T any] struct {
+	w          io.Writer
+	format     Format
+	weightKey  string
+	undirected bool
+	metadata   map[string]any
+	vertexData func(v int) map[string]any
+}
+
+// NewEncoder returns an Encoder that writes to w in the given format.
+func NewEncoder[T any](w io.Writer, format Format) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		format:    format,
+		weightKey: "weight",
+	}
+}
+
+// SetWeightKey sets the edge attribute that holds the weight,
+// the default is "weight". In JGF the weight is written inside
+// the edge metadata.
+func (e *Encoder[T]) SetWeightKey(key string) {
+	e.weightKey = key
+}
+
+// SetUndirected makes the encoder write an undirected graph.
+// Each pair of edges v -> w and w -> v with the same weight
+// is merged into a single undirected edge, the remaining edges are
+// written as they are.
+func (e *Encoder[T]) SetUndirected(undirected bool) {
+	e.undirected = undirected
+}
+
+// SetMetadata sets the metadata of the graph.
+func (e *Encoder[T]) SetMetadata(metadata map[string]any) {
+	e.metadata = metadata
+}
+
+// SetVertexData sets a function that returns the data of each vertex.
+// In JGF the "label" is written as the node label and the other
+// values inside the node metadata.
+func (e *Encoder[T]) SetVertexData(data func(v int) map[string]any) {
+	e.vertexData = data
+}
+
+// Encode writes g to the underlying writer. The vertex v has the ID
+// "v" in JGF and v in node-link.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	edges, err := e.edges(g)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(e.w)
+	write := func(v any) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = bw.Write(b)
+		return err
+	}
+
+	directed := strconv.FormatBool(!e.undirected)
+	if e.format == JGF {
+		bw.WriteString(`{"graph":{"directed":` + directed)
+		if len(e.metadata) > 0 {
+			bw.WriteString(`,"metadata":`)
+			if err := write(e.metadata); err != nil {
+				return err
+			}
+		}
+		bw.WriteString(`,"nodes":{`)
+	} else {
+		bw.WriteString(`{"directed":` + directed + `,"multigraph":true,"graph":`)
+		metadata := e.metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		if err := write(metadata); err != nil {
+			return err
+		}
+		bw.WriteString(`,"nodes":[`)
+	}
+
+	for v := range g.Order() {
+		if v > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString("\n")
+		var data map[string]any
+		if e.vertexData != nil {
+			data = e.vertexData(v)
+		}
+		if e.format == JGF {
+			node := jgfNode{}
+			if label, ok := data["label"].(string); ok {
+				node.Label = label
+				data = maps.Clone(data)
+				delete(data, "label")
+			}
+			if len(data) > 0 {
+				node.Metadata = data
+			}
+			if err := write(strconv.Itoa(v)); err != nil {
+				return err
+			}
+			bw.WriteByte(':')
+			if err := write(node); err != nil {
+				return err
+			}
+		} else {
+			node := make(map[string]any, len(data)+1)
+			maps.Copy(node, data)
+			node["id"] = v
+			if err := write(node); err != nil {
+				return err
+			}
+		}
+	}
+
+	if e.format == JGF {
+		bw.WriteString("},\"edges\":[")
+	} else {
+		bw.WriteString("],\"links\":[")
+	}
+	for i, ed := range edges {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString("\n")
+		var err error
+		if e.format == JGF {
+			err = write(jgfEdge{
+				Source:   strconv.Itoa(ed.v),
+				Target:   strconv.Itoa(ed.w),
+				Metadata: map[string]json.RawMessage{e.weightKey: ed.weight},
+			})
+		} else {
+			err = write(map[string]any{
+				"source":    ed.v,
+				"target":    ed.w,
+				e.weightKey: ed.weight,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if e.format == JGF {
+		bw.WriteString("]}}\n")
+	} else {
+		bw.WriteString("]}\n")
+	}
+	return bw.Flush()
+}
+
+type jgfNode struct {
+	Label    string         `json:"label,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type jgfEdge struct {
+	Source   string                     `json:"source"`
+	Target   string                     `json:"target"`
+	Metadata map[string]json.RawMessage `json:"metadata,omitempty"`
+}
+
+type encEdge struct {
+	v, w   int
+	weight json.RawMessage
+}
+
+// edges returns the edges of g with the weights marshaled, merging the
+// symmetric pairs if the encoder is undirected.
+func (e *Encoder[T]) edges(g grafo.Graph[T]) ([]encEdge, error) {
+	var edges []encEdge
+	for v := range g.Order() {
+		for w, wt := range g.EdgesFrom(v) {
+			b, err := json.Marshal(wt)
+			if err != nil {
+				return nil, err
+			}
+			edges = append(edges, encEdge{v, w, b})
+		}
+	}
+	if !e.undirected {
+		return edges, nil
+	}
+
+	type key struct {
+		v, w   int
+		weight string
+	}
+	count := make(map[key]int)
+	for _, ed := range edges {
+		count[key{ed.v, ed.w, string(ed.weight)}]++
+	}
+	merged := make(map[key]int)
+	res := edges[:0]
+	for _, ed := range edges {
+		k := key{ed.v, ed.w, string(ed.weight)}
+		if merged[k] > 0 {
+			merged[k]--
+			continue
+		}
+		count[k]--
+		back := key{ed.w, ed.v, k.weight}
+		if ed.v != ed.w && count[back] > 0 {
+			count[back]--
+			merged[back]++
+		}
+		res = append(res, ed)
+	}
+	return res, nil
+}
+
+// lineCounter records the offsets of the new lines read from r,
+// so errors found at an offset of the input can report its line.
+type lineCounter struct {
+	r        io.Reader
+	offset   int64
+	newlines []int64
+}
+
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			l.newlines = append(l.newlines, l.offset+int64(i))
+		}
+	}
+	l.offset += int64(n)
+	return n, err
+}
+
+// line returns the line, starting at 1, of the byte at offset.
+func (l *lineCounter) line(offset int64) int {
+	i := sort.Search(len(l.newlines), func(i int) bool {
+		return l.newlines[i] >= offset
+	})
+	return i + 1
+}
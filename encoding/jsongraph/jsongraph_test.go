@@ -0,0 +1,213 @@
+package jsongraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+const jgfSample = `{"graph": {
+	"directed": false,
+	"metadata": {"name": "sample"},
+	"nodes": {
+		"a": {"label": "A", "metadata": {"size": 3}},
+		"b": {},
+		"c": {}
+	},
+	"edges": [
+		{"source": "a", "target": "b", "metadata": {"weight": 1.5}},
+		{"source": "b", "target": "c", "directed": true, "metadata": {"weight": 4}},
+		{"source": "c", "target": "c"}
+	]
+}}`
+
+const nodeLinkSample = `{
+	"directed": true,
+	"multigraph": true,
+	"graph": {"name": "sample"},
+	"nodes": [{"id": 10, "color": "red"}, {"id": 20}],
+	"links": [
+		{"source": 10, "target": 20, "weight": 2},
+		{"source": 10, "target": 20, "weight": 3},
+		{"source": 20, "target": 30, "cost": 1}
+	]
+}`
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           string
+		wantGraph    string
+		wantDirected bool
+		wantNames    []string
+		wantNodes    []map[string]any
+	}{
+		{
+			name:      "jgf",
+			in:        jgfSample,
+			wantGraph: "3 [{0 1}:1.5 (1 2):4 (2 2)]",
+			wantNames: []string{"a", "b", "c"},
+			wantNodes: []map[string]any{{"label": "A", "size": 3.0}, nil, nil},
+		},
+		{
+			name:         "node-link",
+			in:           nodeLinkSample,
+			wantGraph:    "3 [(0 1):2 (0 1):3 (1 2)]",
+			wantDirected: true,
+			wantNames:    []string{"10", "20", "30"},
+			wantNodes:    []map[string]any{{"color": "red"}, nil, nil},
+		},
+		{
+			name:      "jgf version 1",
+			in:        `{"graphs": [{"nodes": [{"id": "x"}], "edges": [{"source": "x", "target": "y", "metadata": {"weight": 7}}]}, {"nodes": []}]}`,
+			wantGraph: "2 [(0 1):7]",
+			wantNames: []string{"x", "y"},
+			wantNodes: []map[string]any{nil, nil},
+			// JGF graphs are directed by default.
+			wantDirected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder[float64](strings.NewReader(tt.in))
+			g, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grafo.String(g); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+			if got := dec.Directed(); got != tt.wantDirected {
+				t.Errorf("got Directed() = %v, want %v", got, tt.wantDirected)
+			}
+			if diff := cmp.Diff(dec.Names(), tt.wantNames); diff != "" {
+				t.Errorf("Names() diff: %s", diff)
+			}
+			if diff := cmp.Diff(dec.NodeData(), tt.wantNodes); diff != "" {
+				t.Errorf("NodeData() diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	for _, in := range []string{jgfSample, nodeLinkSample} {
+		dec := NewDecoder[float64](strings.NewReader(in))
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]any{"name": "sample"}
+		if diff := cmp.Diff(dec.Metadata(), want); diff != "" {
+			t.Errorf("Metadata() diff: %s", diff)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"bad json", "{\n\"nodes\": [\n{\"id\": 1},,\n]}", 3},
+		{"node without id", "{\n\"nodes\": [\n{\"x\": 1}\n]}", 3},
+		{"edge without target", "{\"links\": [\n\n{\"source\": 1}\n]}", 3},
+		{"bad weight", "{\"links\": [\n{\"source\": 1, \"target\": 2, \"weight\": \"x\"}\n]}", 2},
+		{"nodes not array", "{\n\"nodes\": 1}", 2},
+		{"unexpected EOF", "{\"graph\": {\n\"nodes\": {", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder[int](strings.NewReader(tt.in)).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+// capacity is a weight with a custom JSON encoding.
+type capacity int
+
+func (c capacity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%d units", c))
+}
+
+func (c *capacity) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(s, "%d units", (*int)(c))
+	return err
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[capacity](4)
+	g.AddBoth(0, 1, 2)
+	g.Add(1, 2, 5)
+	g.Add(2, 1, 6)
+	g.Add(3, 3, 1)
+
+	for _, format := range []Format{JGF, NodeLink} {
+		for _, undirected := range []bool{false, true} {
+			t.Run(fmt.Sprintf("format %d undirected %v", format, undirected), func(t *testing.T) {
+				var buf bytes.Buffer
+				enc := NewEncoder[capacity](&buf, format)
+				enc.SetWeightKey("cap")
+				enc.SetUndirected(undirected)
+				enc.SetMetadata(map[string]any{"name": "g"})
+				enc.SetVertexData(func(v int) map[string]any {
+					if v == 0 {
+						return map[string]any{"label": "first", "rank": 1}
+					}
+					return nil
+				})
+				if err := enc.Encode(grafo.Sort(g)); err != nil {
+					t.Fatal(err)
+				}
+
+				dec := NewDecoder[capacity](&buf)
+				dec.SetWeightKey("cap")
+				h, err := dec.Decode()
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := "4 [{0 1}:2 (1 2):5 (2 1):6 (3 3):1]"
+				if undirected {
+					// The edges 1 -> 2 and 2 -> 1 have different
+					// weights, so they are written as they are.
+					want = "4 [{0 1}:2 {1 2}:5 {1 2}:6 (3 3):1]"
+				}
+				if got := grafo.String(h); got != want {
+					t.Errorf("got graph %s, want %s\n%s", got, want, buf.String())
+				}
+				if dec.Directed() == undirected {
+					t.Errorf("got Directed() = %v, want %v", dec.Directed(), !undirected)
+				}
+				wantNames := []string{"0", "1", "2", "3"}
+				if diff := cmp.Diff(dec.Names(), wantNames); diff != "" {
+					t.Errorf("Names() diff: %s", diff)
+				}
+				wantNode := map[string]any{"label": "first", "rank": 1.0}
+				if diff := cmp.Diff(dec.NodeData()[0], wantNode); diff != "" {
+					t.Errorf("NodeData()[0] diff: %s", diff)
+				}
+				if got := dec.Metadata()["name"]; got != "g" {
+					t.Errorf("got metadata name %v, want g", got)
+				}
+			})
+		}
+	}
+}
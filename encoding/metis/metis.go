@@ -0,0 +1,361 @@
+// Package metis implements the graph format of the METIS
+// graph partitioning tools (.graph files).
+//
+// A METIS graph is undirected and has no self-loops. The header line
+// "n m [fmt [ncon]]" holds the number of vertices and of undirected
+// edges, followed by n lines, one per vertex, listing its neighbors
+// 1 indexed. Lines starting with '%' are comments.
+//
+// The fmt field has up to 3 digits "abc": if a is 1 each vertex line
+// starts with the vertex size, if b is 1 it follows with ncon vertex
+// weights and if c is 1 each neighbor is followed by the weight of
+// the edge. For example, with fmt 11:
+//
+//	3 2 11
+//	5 2 1
+//	1 1 1 3 4
+//	3 2 4
+//
+// See the METIS manual, section "Graph data structure".
+package metis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Encoder writes graphs in the METIS format.
+type Encoder[T any] struct {
+	w             io.Writer
+	fmtWeight     func(T) string
+	vertexSizes   func(v int) int
+	vertexWeights func(v int) []int
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights. If fmtWeight is nil the
+// weights of the edges are not written.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+	}
+}
+
+// SetVertexSizes sets a function that returns the size of each vertex.
+func (e *Encoder[T]) SetVertexSizes(sizes func(v int) int) {
+	e.vertexSizes = sizes
+}
+
+// SetVertexWeights sets a function that returns the weights of each
+// vertex, all vertices must have the same number of weights.
+func (e *Encoder[T]) SetVertexWeights(weights func(v int) []int) {
+	e.vertexWeights = weights
+}
+
+// Encode writes g to the underlying writer. The vertices are
+// converted to 1 indexed.
+//
+// g must be undirected: each edge v -> w must have a matching
+// edge w -> v with the same weight, and self-loops are not allowed.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	n := g.Order()
+	type edge struct {
+		v, w   int
+		weight string
+	}
+	adj := make([][]edge, n)
+	count := make(map[edge]int)
+	for v := range n {
+		for w, wt := range g.EdgesFrom(v) {
+			if v == w {
+				return fmt.Errorf("self-loop at vertex %d", v)
+			}
+			ed := edge{v: v, w: w}
+			if e.fmtWeight != nil {
+				ed.weight = e.fmtWeight(wt)
+			}
+			adj[v] = append(adj[v], ed)
+			count[ed]++
+		}
+	}
+	m := 0
+	for ed, c := range count {
+		if count[edge{ed.w, ed.v, ed.weight}] != c {
+			return fmt.Errorf("graph is not undirected: edge %d -> %d has no matching edge", ed.v, ed.w)
+		}
+		m += c
+	}
+
+	ncon := -1
+	weights := make([][]int, n)
+	if e.vertexWeights != nil {
+		for v := range n {
+			weights[v] = e.vertexWeights(v)
+			if ncon >= 0 && len(weights[v]) != ncon {
+				return fmt.Errorf("vertex %d has %d weights, want %d", v, len(weights[v]), ncon)
+			}
+			ncon = len(weights[v])
+		}
+	}
+
+	bw := bufio.NewWriter(e.w)
+	flags := flag(e.vertexSizes != nil) + flag(ncon > 0) + flag(e.fmtWeight != nil)
+	fmt.Fprintf(bw, "%d %d", n, m/2)
+	switch {
+	case ncon > 0:
+		fmt.Fprintf(bw, " %s %d", flags, ncon)
+	case flags != "000":
+		fmt.Fprintf(bw, " %s", flags)
+	}
+	bw.WriteByte('\n')
+
+	for v := range n {
+		var fields []string
+		if e.vertexSizes != nil {
+			fields = append(fields, strconv.Itoa(e.vertexSizes(v)))
+		}
+		if ncon > 0 {
+			for _, wt := range weights[v] {
+				fields = append(fields, strconv.Itoa(wt))
+			}
+		}
+		for _, ed := range adj[v] {
+			fields = append(fields, strconv.Itoa(ed.w+1))
+			if e.fmtWeight != nil {
+				fields = append(fields, ed.weight)
+			}
+		}
+		for i, f := range fields {
+			if i > 0 {
+				bw.WriteByte(' ')
+			}
+			bw.WriteString(f)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Decoder reads graphs in the METIS format.
+type Decoder[T any] struct {
+	r           io.Reader
+	parseWeight func(string) (T, error)
+
+	sizes   []int
+	weights [][]int
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weights of the edges. If the file has
+// no edge weights, the edges have the weight parsed from "1",
+// the METIS default.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+	}
+}
+
+// VertexSizes returns the sizes of the vertices of the last decoded
+// graph, or nil if the file has no vertex sizes.
+func (d *Decoder[T]) VertexSizes() []int { return d.sizes }
+
+// VertexWeights returns the weights of the vertices of the last
+// decoded graph, VertexWeights()[v] holds the ncon weights of v.
+// It returns nil if the file has no vertex weights.
+func (d *Decoder[T]) VertexWeights() [][]int { return d.weights }
+
+// Decode reads a graph from the underlying reader. Each undirected
+// edge is listed twice in the file, once per endpoint, and is decoded
+// as the edges v -> w and w -> v. Self-loops are rejected.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	sc := bufio.NewScanner(d.r)
+	sc.Buffer(nil, 1<<30)
+	line := 0
+	errorf := func(format string, args ...any) error {
+		return &encoding.SyntaxError{Line: line, Err: fmt.Errorf(format, args...)}
+	}
+	wrap := func(err error) error {
+		return &encoding.SyntaxError{Line: line, Err: err}
+	}
+	// next returns the fields of the next line that is not a comment.
+	next := func() ([][]byte, error) {
+		for sc.Scan() {
+			line++
+			text := bytes.TrimSpace(sc.Bytes())
+			if len(text) > 0 && text[0] == '%' {
+				continue
+			}
+			return bytes.Fields(text), nil
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	// Header, empty lines before it are skipped.
+	var header [][]byte
+	for len(header) == 0 {
+		var err error
+		header, err = next()
+		if err == io.EOF {
+			line++
+			return nil, errorf("missing header line")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(header) < 2 || len(header) > 4 {
+		return nil, errorf("got %d elements in the header line, want 2 to 4", len(header))
+	}
+	nums := make([]int, len(header))
+	for i, f := range header {
+		var err error
+		if nums[i], err = strconv.Atoi(string(f)); err != nil {
+			return nil, wrap(err)
+		}
+		if nums[i] < 0 {
+			return nil, errorf("negative number in the header line: %d", nums[i])
+		}
+	}
+	n, m := nums[0], nums[1]
+	var hasSize, hasWeights, hasEdgeWeights bool
+	if len(header) > 2 {
+		f := string(header[2])
+		if len(f) > 3 || len(bytes.Trim(header[2], "01")) > 0 {
+			return nil, errorf("invalid fmt %q", f)
+		}
+		f = strings.Repeat("0", 3-len(f)) + f
+		hasSize, hasWeights, hasEdgeWeights = f[0] == '1', f[1] == '1', f[2] == '1'
+	}
+	ncon := 0
+	if hasWeights {
+		ncon = 1
+	}
+	if len(header) > 3 {
+		if !hasWeights {
+			return nil, errorf("ncon given without vertex weights in fmt")
+		}
+		ncon = nums[3]
+	}
+
+	var unit T
+	if !hasEdgeWeights {
+		var err error
+		if unit, err = d.parseWeight("1"); err != nil {
+			return nil, fmt.Errorf("parsing the default weight: %w", err)
+		}
+	}
+
+	var sizes []int
+	if hasSize {
+		sizes = make([]int, n)
+	}
+	var weights [][]int
+	if hasWeights {
+		weights = make([][]int, n)
+	}
+	g := multigraph.New[T](n)
+	count := 0
+	for v := range n {
+		fields, err := next()
+		if err == io.EOF {
+			line++
+			return nil, errorf("got %d vertex lines, want %d", v, n)
+		}
+		if err != nil {
+			return nil, err
+		}
+		readInt := func() (int, error) {
+			if len(fields) == 0 {
+				return 0, errorf("missing vertex size or weight")
+			}
+			x, err := strconv.Atoi(string(fields[0]))
+			if err != nil {
+				return 0, wrap(err)
+			}
+			fields = fields[1:]
+			return x, nil
+		}
+		if hasSize {
+			if sizes[v], err = readInt(); err != nil {
+				return nil, err
+			}
+		}
+		if hasWeights {
+			weights[v] = make([]int, ncon)
+			for i := range ncon {
+				if weights[v][i], err = readInt(); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		step := 1
+		if hasEdgeWeights {
+			step = 2
+		}
+		if len(fields)%step != 0 {
+			return nil, errorf("neighbor without weight")
+		}
+		for i := 0; i < len(fields); i += step {
+			w, err := strconv.Atoi(string(fields[i]))
+			if err != nil {
+				return nil, wrap(err)
+			}
+			if w < 1 || w > n {
+				return nil, errorf("vertex %d out of valid range [1, %d]", w, n)
+			}
+			w--
+			if w == v {
+				return nil, errorf("self-loop at vertex %d", v+1)
+			}
+			weight := unit
+			if hasEdgeWeights {
+				if weight, err = d.parseWeight(string(fields[i+1])); err != nil {
+					return nil, wrap(err)
+				}
+			}
+			g.Add(v, w, weight)
+			count++
+		}
+	}
+	for {
+		fields, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			return nil, errorf("got more than %d vertex lines", n)
+		}
+	}
+	if count != 2*m {
+		return nil, errorf("got %d adjacencies, want %d for the %d edges declared in the header", count, 2*m, m)
+	}
+
+	d.sizes = sizes
+	d.weights = weights
+	return g, nil
+}
+
+func flag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
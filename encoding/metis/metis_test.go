@@ -0,0 +1,140 @@
+package metis
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantGraph   string
+		wantSizes   []int
+		wantWeights [][]int
+	}{
+		{
+			name:      "unweighted",
+			in:        "% A path with an isolated vertex.\n4 2\n2\n1 3\n2\n\n",
+			wantGraph: "4 [{0 1}:1 {1 2}:1]",
+		},
+		{
+			name:        "weighted",
+			in:          "3 2 11\n5 2 1\n1 1 1 3 4\n3 2 4\n",
+			wantGraph:   "3 [{0 1}:1 {1 2}:4]",
+			wantWeights: [][]int{{5}, {1}, {3}},
+		},
+		{
+			name:        "sizes and ncon",
+			in:          "2 1 110 2\n% comment inside\n7 1 2 2\n8 3 4 1\n",
+			wantGraph:   "2 [{0 1}:1]",
+			wantSizes:   []int{7, 8},
+			wantWeights: [][]int{{1, 2}, {3, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), strconv.Atoi)
+			g, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grafo.String(g); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+			if diff := cmp.Diff(dec.VertexSizes(), tt.wantSizes); diff != "" {
+				t.Errorf("VertexSizes() diff: %s", diff)
+			}
+			if diff := cmp.Diff(dec.VertexWeights(), tt.wantWeights); diff != "" {
+				t.Errorf("VertexWeights() diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"empty", "% comment\n", 2},
+		{"bad header", "3\n", 1},
+		{"invalid fmt", "2 1 12\n", 1},
+		{"ncon without weights", "2 1 1 2\n", 1},
+		{"missing vertex lines", "3 1\n2\n1\n", 4},
+		{"vertex out of range", "2 1\n2\n3\n", 3},
+		{"self-loop", "2 1\n1\n", 2},
+		{"neighbor without weight", "2 1 1\n2 5\n1\n", 3},
+		{"wrong number of edges", "2 2\n2\n1\n", 3},
+		{"extra lines", "1 0\n\n1\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.in), strconv.Atoi).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[int](4)
+	g.AddBoth(0, 1, 3)
+	g.AddBoth(1, 3, 2)
+	g.AddBoth(0, 3, 9)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, strconv.Itoa)
+	enc.SetVertexSizes(func(v int) int { return v + 10 })
+	enc.SetVertexWeights(func(v int) []int { return []int{v, 2 * v} })
+	if err := enc.Encode(grafo.Sort(g)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "4 3 111 2\n") {
+		t.Errorf("got wrong header in:\n%s", buf.String())
+	}
+
+	dec := NewDecoder(&buf, strconv.Atoi)
+	h, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := grafo.String(h), grafo.String(g); got != want {
+		t.Errorf("got graph %s, want %s", got, want)
+	}
+	if diff := cmp.Diff(dec.VertexSizes(), []int{10, 11, 12, 13}); diff != "" {
+		t.Errorf("VertexSizes() diff: %s", diff)
+	}
+	if diff := cmp.Diff(dec.VertexWeights(), [][]int{{0, 0}, {1, 2}, {2, 4}, {3, 6}}); diff != "" {
+		t.Errorf("VertexWeights() diff: %s", diff)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	directed := grafo.NewMutable[int](2)
+	directed.Add(0, 1, 1)
+	loop := grafo.NewMutable[int](1)
+	loop.Add(0, 0, 1)
+	asymmetric := grafo.NewMutable[int](2)
+	asymmetric.Add(0, 1, 1)
+	asymmetric.Add(1, 0, 2)
+
+	for _, g := range []grafo.Graph[int]{directed, loop, asymmetric} {
+		if err := NewEncoder(new(bytes.Buffer), strconv.Itoa).Encode(g); err == nil {
+			t.Errorf("Encode(%s) succeeded, want error", grafo.String(g))
+		}
+	}
+}
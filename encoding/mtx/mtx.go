@@ -0,0 +1,318 @@
+// Package mtx implements the Matrix Market exchange format for
+// sparse matrices, read as adjacency matrices of graphs.
+//
+// Only the coordinate format is supported. A file looks like:
+//
+//	%%MatrixMarket matrix coordinate real general
+//	% Comments start with %.
+//	3 3 2
+//	1 2 0.5
+//	3 1 4
+//
+// The header declares the field of the values (real, double, integer
+// or pattern) and the symmetry (general or symmetric). The size line
+// holds the number of rows, columns and entries, and each entry
+// "i j value" is the edge i -[value]-> j. Indices are 1 indexed.
+// Pattern matrices have no values.
+//
+// See https://math.nist.gov/MatrixMarket/formats.html.
+package mtx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Header holds the qualifiers of a Matrix Market file.
+type Header struct {
+	Field    string // real, double, integer or pattern.
+	Symmetry string // general or symmetric.
+}
+
+// Encoder writes graphs in the Matrix Market format.
+type Encoder[T any] struct {
+	w         io.Writer
+	fmtWeight func(T) string
+	symmetric bool
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights. If fmtWeight is nil a pattern
+// matrix is written, without the weights. The field is integer
+// for integer weights and real otherwise.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+	}
+}
+
+// SetSymmetric makes the encoder write a symmetric matrix, only the
+// entries of the lower triangle are written. Encode fails if the
+// graph has an edge v -> w without an edge w -> v of the same weight.
+func (e *Encoder[T]) SetSymmetric(symmetric bool) {
+	e.symmetric = symmetric
+}
+
+type entry struct {
+	i, j   int
+	weight string
+}
+
+// Encode writes g to the underlying writer.
+// The vertices are converted to 1 indexed.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	var entries []entry
+	for v := range g.Order() {
+		for w, wt := range g.EdgesFrom(v) {
+			ent := entry{i: v, j: w}
+			if e.fmtWeight != nil {
+				ent.weight = e.fmtWeight(wt)
+			}
+			entries = append(entries, ent)
+		}
+	}
+
+	h := Header{Field: "pattern", Symmetry: "general"}
+	if e.fmtWeight != nil {
+		h.Field = "real"
+		switch reflect.TypeFor[T]().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			h.Field = "integer"
+		}
+	}
+	if e.symmetric {
+		h.Symmetry = "symmetric"
+		var err error
+		if entries, err = lowerTriangle(entries); err != nil {
+			return err
+		}
+	}
+
+	bw := bufio.NewWriter(e.w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate %s %s\n", h.Field, h.Symmetry)
+	fmt.Fprintf(bw, "%d %d %d\n", g.Order(), g.Order(), len(entries))
+	for _, ent := range entries {
+		if e.fmtWeight == nil {
+			fmt.Fprintf(bw, "%d %d\n", ent.i+1, ent.j+1)
+		} else {
+			fmt.Fprintf(bw, "%d %d %s\n", ent.i+1, ent.j+1, ent.weight)
+		}
+	}
+	return bw.Flush()
+}
+
+// lowerTriangle returns the entries i >= j, checking that each
+// of them matches the symmetric entry.
+func lowerTriangle(entries []entry) ([]entry, error) {
+	upper := make(map[entry]int)
+	for _, ent := range entries {
+		if ent.i < ent.j {
+			upper[ent]++
+		}
+	}
+	var res []entry
+	for _, ent := range entries {
+		if ent.i < ent.j {
+			continue
+		}
+		if ent.i > ent.j {
+			back := entry{ent.j, ent.i, ent.weight}
+			if upper[back] == 0 {
+				return nil, fmt.Errorf("graph is not symmetric: edge %d -> %d has no matching edge", ent.i, ent.j)
+			}
+			upper[back]--
+		}
+		res = append(res, ent)
+	}
+	for ent, count := range upper {
+		if count > 0 {
+			return nil, fmt.Errorf("graph is not symmetric: edge %d -> %d has no matching edge", ent.i, ent.j)
+		}
+	}
+	return res, nil
+}
+
+// Decoder reads graphs in the Matrix Market format.
+type Decoder[T any] struct {
+	r           io.Reader
+	parseWeight func(string) (T, error)
+	header      Header
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the values. The edges of pattern matrices
+// have the zero value of T.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+	}
+}
+
+// Header returns the header of the last decoded matrix.
+func (d *Decoder[T]) Header() Header { return d.header }
+
+// Decode reads a graph from the underlying reader. The matrix must
+// be square, each entry i j of a symmetric matrix off the diagonal
+// is decoded as the edges i -> j and j -> i.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	sc := bufio.NewScanner(d.r)
+	line := 0
+	errorf := func(format string, args ...any) error {
+		return &encoding.SyntaxError{Line: line, Err: fmt.Errorf(format, args...)}
+	}
+
+	// Header.
+	line++
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, &encoding.SyntaxError{Line: line, Err: io.ErrUnexpectedEOF}
+	}
+	h, err := parseHeader(sc.Text())
+	if err != nil {
+		return nil, &encoding.SyntaxError{Line: line, Err: err}
+	}
+
+	// next returns the fields of the next line that is not
+	// empty nor a comment.
+	next := func() ([][]byte, error) {
+		for sc.Scan() {
+			line++
+			text := bytes.TrimSpace(sc.Bytes())
+			if len(text) == 0 || text[0] == '%' {
+				continue
+			}
+			return bytes.Fields(text), nil
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	// Size line.
+	fields, err := next()
+	if err == io.EOF {
+		line++
+		return nil, errorf("missing size line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 3 {
+		return nil, errorf("got %d elements in the size line, want 3", len(fields))
+	}
+	var size [3]int
+	for i, f := range fields {
+		if size[i], err = strconv.Atoi(string(f)); err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		if size[i] < 0 {
+			return nil, errorf("negative number in the size line: %d", size[i])
+		}
+	}
+	n, nnz := size[0], size[2]
+	if size[0] != size[1] {
+		return nil, errorf("got a %dx%d matrix, want a square matrix", size[0], size[1])
+	}
+
+	nfields := 3
+	if h.Field == "pattern" {
+		nfields = 2
+	}
+	g := multigraph.New[T](n)
+	count := 0
+	for {
+		fields, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != nfields {
+			return nil, errorf("got %d elements in one line, want %d", len(fields), nfields)
+		}
+		if count++; count > nnz {
+			return nil, errorf("got more than %d entries declared in the size line", nnz)
+		}
+		v, err := parseIndex(fields[0], n)
+		if err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		w, err := parseIndex(fields[1], n)
+		if err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		var weight T
+		if h.Field != "pattern" {
+			if weight, err = d.parseWeight(string(fields[2])); err != nil {
+				return nil, &encoding.SyntaxError{Line: line, Err: err}
+			}
+		}
+		if h.Symmetry == "symmetric" && v != w {
+			g.AddBoth(v, w, weight)
+		} else {
+			g.Add(v, w, weight)
+		}
+	}
+	if count != nnz {
+		return nil, errorf("got %d entries, want %d declared in the size line", count, nnz)
+	}
+
+	d.header = h
+	return g, nil
+}
+
+func parseHeader(s string) (Header, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) != 5 || fields[0] != "%%matrixmarket" {
+		return Header{}, errors.New("missing %%MatrixMarket header")
+	}
+	if fields[1] != "matrix" {
+		return Header{}, fmt.Errorf("unsupported object %q", fields[1])
+	}
+	if fields[2] != "coordinate" {
+		return Header{}, fmt.Errorf("unsupported format %q, want coordinate", fields[2])
+	}
+	h := Header{Field: fields[3], Symmetry: fields[4]}
+	switch h.Field {
+	case "real", "double", "integer", "pattern":
+	default:
+		return Header{}, fmt.Errorf("unsupported field %q", h.Field)
+	}
+	switch h.Symmetry {
+	case "general", "symmetric":
+	default:
+		return Header{}, fmt.Errorf("unsupported symmetry %q", h.Symmetry)
+	}
+	return h, nil
+}
+
+// parseIndex parses a 1 indexed index in the range [1, n]
+// and returns it 0 indexed.
+func parseIndex(field []byte, n int) (int, error) {
+	i, err := strconv.Atoi(string(field))
+	if err != nil {
+		return 0, err
+	}
+	if i < 1 || i > n {
+		return 0, fmt.Errorf("index %d out of valid range [1, %d]", i, n)
+	}
+	return i - 1, nil
+}
@@ -0,0 +1,156 @@
+package mtx
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+)
+
+func parseFloat(s string) (float64, error) { return strconv.ParseFloat(s, 64) }
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantGraph  string
+		wantHeader Header
+	}{
+		{
+			name: "general",
+			in: `%%MatrixMarket matrix coordinate real general
+% A comment.
+
+3 3 3
+1 2 0.5
+3 1 4
+2 2 -1e2
+`,
+			wantGraph:  "3 [(0 1):0.5 (1 1):-100 (2 0):4]",
+			wantHeader: Header{Field: "real", Symmetry: "general"},
+		},
+		{
+			name: "symmetric",
+			in: `%%MatrixMarket matrix coordinate integer symmetric
+3 3 2
+2 1 7
+3 3 1
+`,
+			wantGraph:  "3 [{0 1}:7 (2 2):1]",
+			wantHeader: Header{Field: "integer", Symmetry: "symmetric"},
+		},
+		{
+			name: "pattern",
+			in: `%%MatrixMarket Matrix Coordinate Pattern General
+2 2 2
+1 2
+2 1
+`,
+			wantGraph:  "2 [{0 1}]",
+			wantHeader: Header{Field: "pattern", Symmetry: "general"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in), parseFloat)
+			g, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grafo.String(g); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+			if got := dec.Header(); got != tt.wantHeader {
+				t.Errorf("got header %+v, want %+v", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	const header = "%%MatrixMarket matrix coordinate integer general\n"
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{"empty", "", 1},
+		{"missing header", "2 2 1\n1 2 3\n", 1},
+		{"array format", "%%MatrixMarket matrix array real general\n", 1},
+		{"complex", "%%MatrixMarket matrix coordinate complex general\n", 1},
+		{"skew-symmetric", "%%MatrixMarket matrix coordinate real skew-symmetric\n", 1},
+		{"missing size line", header + "% comment\n", 3},
+		{"not square", header + "2 3 1\n", 2},
+		{"index out of range", header + "2 2 1\n\n1 3 5\n", 4},
+		{"missing value", header + "2 2 1\n1 2\n", 3},
+		{"bad value", header + "2 2 1\n1 2 x\n", 3},
+		{"too many entries", header + "2 2 1\n1 2 1\n2 1 1\n", 4},
+		{"too few entries", header + "2 2 2\n1 2 1\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tt.in), strconv.Atoi).Decode()
+			var serr *encoding.SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Decode() error = %v, want *encoding.SyntaxError", err)
+			}
+			if serr.Line != tt.wantLine {
+				t.Errorf("got error %v at line %d, want line %d", err, serr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := grafo.NewMutable[int](4)
+	g.AddBoth(0, 1, 3)
+	g.AddBoth(1, 3, -2)
+	g.Add(2, 2, 5)
+
+	tests := []struct {
+		name       string
+		symmetric  bool
+		fmtWeight  func(int) string
+		wantHeader string
+		wantGraph  string
+	}{
+		{"general", false, strconv.Itoa, "%%MatrixMarket matrix coordinate integer general\n4 4 5\n", "4 [{0 1}:3 {1 3}:-2 (2 2):5]"},
+		{"symmetric", true, strconv.Itoa, "%%MatrixMarket matrix coordinate integer symmetric\n4 4 3\n", "4 [{0 1}:3 {1 3}:-2 (2 2):5]"},
+		{"pattern", true, nil, "%%MatrixMarket matrix coordinate pattern symmetric\n4 4 3\n", "4 [{0 1} {1 3} (2 2)]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf, tt.fmtWeight)
+			enc.SetSymmetric(tt.symmetric)
+			if err := enc.Encode(g); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.wantHeader) {
+				t.Errorf("got output\n%s\nwant prefix\n%s", buf.String(), tt.wantHeader)
+			}
+			h, err := NewDecoder(&buf, strconv.Atoi).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := grafo.String(h); got != tt.wantGraph {
+				t.Errorf("got graph %s, want %s", got, tt.wantGraph)
+			}
+		})
+	}
+}
+
+func TestEncodeNotSymmetric(t *testing.T) {
+	g := grafo.NewMutable[int](2)
+	g.Add(0, 1, 1)
+	g.Add(1, 0, 2)
+	enc := NewEncoder(new(bytes.Buffer), strconv.Itoa)
+	enc.SetSymmetric(true)
+	if err := enc.Encode(g); err == nil {
+		t.Error("Encode() succeeded, want error")
+	}
+}
@@ -0,0 +1,146 @@
+// Package simple implements a minimal text format for graphs.
+//
+// The first line holds the number of vertices, each following
+// line holds one edge v -[weight]-> w as:
+//
+//	v w weight
+//
+// Vertices are 0 indexed and empty lines are ignored.
+package simple
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rschio/grafo"
+	"github.com/rschio/grafo/encoding"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+// Encoder writes graphs in the simple format.
+type Encoder[T any] struct {
+	w         io.Writer
+	fmtWeight func(T) string
+}
+
+// NewEncoder returns an Encoder that writes to w and uses
+// fmtWeight to format the weights.
+func NewEncoder[T any](w io.Writer, fmtWeight func(T) string) *Encoder[T] {
+	return &Encoder[T]{
+		w:         w,
+		fmtWeight: fmtWeight,
+	}
+}
+
+// Encode writes g to the underlying writer.
+func (e *Encoder[T]) Encode(g grafo.Graph[T]) error {
+	_, err := fmt.Fprintln(e.w, g.Order())
+	if err != nil {
+		return err
+	}
+	for v := range g.Order() {
+		for w, wt := range g.EdgesFrom(v) {
+			_, err := fmt.Fprintf(e.w, "%d %d %s\n", v, w, e.fmtWeight(wt))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Decoder reads graphs in the simple format.
+type Decoder[T any] struct {
+	r           io.Reader
+	parseWeight func(string) (T, error)
+}
+
+// NewDecoder returns a Decoder that reads from r and uses
+// parseWeight to parse the weights.
+func NewDecoder[T any](r io.Reader, parseWeight func(string) (T, error)) *Decoder[T] {
+	return &Decoder[T]{
+		r:           r,
+		parseWeight: parseWeight,
+	}
+}
+
+// Decode reads a graph from the underlying reader.
+// Malformed input is reported as an *encoding.SyntaxError.
+func (d *Decoder[T]) Decode() (grafo.Graph[T], error) {
+	sc := bufio.NewScanner(d.r)
+
+	n, err := readNumberOfVertices(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	g := multigraph.New[T](n)
+	sep := []byte(" ")
+	for line := 2; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		parts := bytes.Split(text, sep)
+		if len(parts) != 3 {
+			err := fmt.Errorf("got %d elements in one line, want 3", len(parts))
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		vv := strings.TrimSpace(string(parts[0]))
+		ww := strings.TrimSpace(string(parts[1]))
+		wt := strings.TrimSpace(string(parts[2]))
+		v, err := parseVertex(vv, n)
+		if err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		w, err := parseVertex(ww, n)
+		if err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		weight, err := d.parseWeight(wt)
+		if err != nil {
+			return nil, &encoding.SyntaxError{Line: line, Err: err}
+		}
+		g.Add(v, w, weight)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func readNumberOfVertices(sc *bufio.Scanner) (int, error) {
+	if ok := sc.Scan(); !ok {
+		if err := sc.Err(); err != nil {
+			return 0, fmt.Errorf("failed to read number of vertices: %w", err)
+		}
+		return 0, &encoding.SyntaxError{Line: 1, Err: io.ErrUnexpectedEOF}
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(sc.Text()))
+	if err != nil {
+		err = fmt.Errorf("failed to read number of vertices: %w", err)
+		return 0, &encoding.SyntaxError{Line: 1, Err: err}
+	}
+	if n < 0 {
+		err := fmt.Errorf("negative number of vertices: %d", n)
+		return 0, &encoding.SyntaxError{Line: 1, Err: err}
+	}
+	return n, nil
+}
+
+func parseVertex(s string, n int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v >= n {
+		return 0, fmt.Errorf("vertex %d out of valid range", v)
+	}
+	return v, nil
+}
@@ -0,0 +1,40 @@
+package grafo_test
+
+import (
+	"fmt"
+
+	"github.com/rschio/grafo"
+)
+
+// route is the weight of a route, its cost and number of hops.
+type route struct {
+	cost, hops int
+}
+
+// This example finds the cheapest routes, and among them the ones
+// with the fewest hops, with a semiring of (cost, hops) weights.
+func ExampleShortestPathSemiring() {
+	g := grafo.NewMutable[route](4)
+	g.Add(0, 1, route{1, 1})
+	g.Add(1, 2, route{1, 1})
+	g.Add(2, 3, route{1, 1})
+	g.Add(0, 3, route{3, 1})
+
+	s := grafo.Semiring[route]{
+		Zero: route{cost: grafo.InfFor[int]()},
+		One:  route{},
+		Extend: func(a, b route) route {
+			return route{a.cost + b.cost, a.hops + b.hops}
+		},
+		Less: func(a, b route) bool {
+			if a.cost != b.cost {
+				return a.cost < b.cost
+			}
+			return a.hops < b.hops
+		},
+	}
+	path, dist := grafo.ShortestPathSemiring(g, s, 0, 3)
+	fmt.Println(path, dist.cost, dist.hops)
+	// Output:
+	// [0 3] 3 1
+}
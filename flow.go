@@ -0,0 +1,378 @@
+package grafo
+
+// flowNetwork is an array-based residual graph of a flow network.
+// Each edge v -> w of capacity c and cost k is an arc v -> w of
+// capacity c and cost k and a reverse arc w -> v of capacity 0 and
+// cost -k, pushing flow along an arc moves its capacity to the
+// reverse arc.
+type flowNetwork[T IntegerOrFloat] struct {
+	start []int // the arcs from v are start[v]:start[v+1]
+	head  []int // head[a] is the vertex the arc a goes to
+	rev   []int // rev[a] is the reverse arc of a
+	cap   []T   // cap[a] is the residual capacity of a
+	orig  []T   // orig[a] is the capacity of a, 0 for the reverse arcs
+	cost  []T   // cost[a] is the cost of a unit of flow along a
+	eps   T     // the capacities and flows up to eps are treated as 0
+}
+
+// newFlowNetwork returns the residual graph of g with no flow,
+// the weights are the capacities and the costs are 0.
+// The self-loops and the edges without a positive capacity are skipped.
+func newFlowNetwork[T IntegerOrFloat](g Graph[T]) *flowNetwork[T] {
+	return buildFlowNetwork(g, func(c T) (T, T) { return c, 0 })
+}
+
+// buildFlowNetwork returns the residual graph of g with no flow, arc
+// returns the capacity and the cost of each weight.
+// The self-loops and the edges without a positive capacity are skipped.
+func buildFlowNetwork[T IntegerOrFloat, W any](g Graph[W], arc func(weight W) (capacity, cost T)) *flowNetwork[T] {
+	n := g.Order()
+	start := make([]int, n+1)
+	for v := range n {
+		for w, weight := range g.EdgesFrom(v) {
+			if c, _ := arc(weight); v != w && c > 0 {
+				start[v+1]++
+				start[w+1]++
+			}
+		}
+	}
+	for v := range n {
+		start[v+1] += start[v]
+	}
+	m := start[n]
+	nw := &flowNetwork[T]{
+		start: start,
+		head:  make([]int, m),
+		rev:   make([]int, m),
+		cap:   make([]T, m),
+		orig:  make([]T, m),
+		cost:  make([]T, m),
+	}
+	next := make([]int, n)
+	copy(next, start)
+	for v := range n {
+		for w, weight := range g.EdgesFrom(v) {
+			c, k := arc(weight)
+			if v == w || !(c > 0) {
+				continue
+			}
+			a, b := next[v], next[w]
+			next[v]++
+			next[w]++
+			nw.head[a], nw.rev[a], nw.cap[a], nw.orig[a], nw.cost[a] = w, b, c, c, k
+			nw.head[b], nw.rev[b], nw.cost[b] = v, a, -k
+		}
+	}
+	return nw
+}
+
+func (nw *flowNetwork[T]) order() int { return len(nw.start) - 1 }
+
+// push pushes d units of flow along the arc a.
+func (nw *flowNetwork[T]) push(a int, d T) {
+	nw.cap[a] -= d
+	nw.cap[nw.rev[a]] += d
+}
+
+// flowGraph returns the flow between each pair of the first n vertices,
+// the net flow if net is true, the pairs without a positive flow
+// are omitted.
+func (nw *flowNetwork[T]) flowGraph(n int, net bool) *Immutable[T] {
+	flows := NewMutable[T](n)
+	for v := range n {
+		for a := nw.start[v]; a < nw.start[v+1]; a++ {
+			// The flow is the residual capacity of the reverse arc,
+			// the capacity minus the residual capacity is NaN for
+			// infinite capacities.
+			if w, f := nw.head[a], nw.cap[nw.rev[a]]; w < n && nw.orig[a] > 0 && f > nw.eps {
+				flows.Add(v, w, flows.Weight(v, w)+f)
+			}
+		}
+	}
+	if !net {
+		return Sort(flows)
+	}
+	res := NewMutable[T](n)
+	for v := range n {
+		for w, f := range flows.EdgesFrom(v) {
+			if back := flows.Weight(w, v); f > back && f-back > nw.eps {
+				res.Add(v, w, f-back)
+			}
+		}
+	}
+	return Sort(res)
+}
+
+// maxFlow computes a maximum flow from s to t with the algorithm a.
+// If s equals t or there is a path of arcs of infinite capacity
+// from s to t, the flow is inf and finite is false.
+func (nw *flowNetwork[T]) maxFlow(s, t int, a MaxFlowAlgorithm) (flow T, finite bool) {
+	inf := InfFor[T]()
+	if s == t {
+		return inf, false
+	}
+	// Find a path of infinite capacity.
+	visited := make([]bool, nw.order())
+	visited[s] = true
+	queue := newQueue(10)
+	queue.Push(s)
+	for queue.Len() > 0 {
+		v := queue.Pop()
+		for a := nw.start[v]; a < nw.start[v+1]; a++ {
+			if w := nw.head[a]; !visited[w] && nw.cap[a] == inf {
+				visited[w] = true
+				queue.Push(w)
+			}
+		}
+	}
+	if visited[t] {
+		return inf, false
+	}
+
+	switch a {
+	case Dinic:
+		return nw.dinic(s, t), true
+	case PushRelabel:
+		return nw.pushRelabel(s, t), true
+	}
+	return nw.edmondsKarp(s, t), true
+}
+
+// edmondsKarp computes a maximum flow from s to t using the
+// Edmonds-Karp algorithm, it pushes flow along the shortest paths
+// from s to t until t can't be reached.
+func (nw *flowNetwork[T]) edmondsKarp(s, t int) T {
+	n := nw.order()
+	prev := make([]int, n) // prev[v] is the arc to v in the path
+	queue := newQueue(n)
+	inf := InfFor[T]()
+	var flow T
+	for {
+		for i := range prev {
+			prev[i] = -1
+		}
+		queue.Push(s)
+		for queue.Len() > 0 {
+			v := queue.Pop()
+			for a := nw.start[v]; a < nw.start[v+1]; a++ {
+				if w := nw.head[a]; w != s && prev[w] == -1 && nw.cap[a] > nw.eps {
+					prev[w] = a
+					queue.Push(w)
+				}
+			}
+		}
+		if prev[t] == -1 {
+			return flow
+		}
+
+		d := inf
+		for v := t; v != s; v = nw.head[nw.rev[prev[v]]] {
+			d = min(d, nw.cap[prev[v]])
+		}
+		for v := t; v != s; v = nw.head[nw.rev[prev[v]]] {
+			nw.push(prev[v], d)
+		}
+		if flow = addInf(flow, d); flow == inf {
+			return flow
+		}
+	}
+}
+
+// reachable returns the vertices that can be reached from s
+// in the residual graph.
+func (nw *flowNetwork[T]) reachable(s int) []bool {
+	visited := make([]bool, nw.order())
+	visited[s] = true
+	queue := newQueue(10)
+	queue.Push(s)
+	for queue.Len() > 0 {
+		v := queue.Pop()
+		for a := nw.start[v]; a < nw.start[v+1]; a++ {
+			if w := nw.head[a]; !visited[w] && nw.cap[a] > nw.eps {
+				visited[w] = true
+				queue.Push(w)
+			}
+		}
+	}
+	return visited
+}
+
+// dinic computes a maximum flow from s to t using Dinic's algorithm,
+// it finds blocking flows in the level graph of the shortest paths
+// from s until t can't be reached.
+func (nw *flowNetwork[T]) dinic(s, t int) T {
+	n := nw.order()
+	level := make([]int, n)
+	next := make([]int, n) // next[v] is the next arc from v to try
+	queue := newQueue(n)
+	inf := InfFor[T]()
+
+	// augment pushes flow along a path from v to t in the level graph,
+	// at most limit, and returns the flow pushed.
+	var augment func(v int, limit T) T
+	augment = func(v int, limit T) T {
+		if v == t {
+			return limit
+		}
+		for ; next[v] < nw.start[v+1]; next[v]++ {
+			a := next[v]
+			w := nw.head[a]
+			if !(nw.cap[a] > nw.eps) || level[w] != level[v]+1 {
+				continue
+			}
+			if d := augment(w, min(limit, nw.cap[a])); d > 0 {
+				nw.push(a, d)
+				return d
+			}
+		}
+		return 0
+	}
+
+	var flow T
+	for {
+		for i := range level {
+			level[i] = -1
+		}
+		level[s] = 0
+		queue.Push(s)
+		for queue.Len() > 0 {
+			v := queue.Pop()
+			for a := nw.start[v]; a < nw.start[v+1]; a++ {
+				if w := nw.head[a]; level[w] == -1 && nw.cap[a] > nw.eps {
+					level[w] = level[v] + 1
+					queue.Push(w)
+				}
+			}
+		}
+		if level[t] == -1 {
+			return flow
+		}
+
+		copy(next, nw.start)
+		for {
+			d := augment(s, inf)
+			if !(d > 0) {
+				break
+			}
+			if flow = addInf(flow, d); flow == inf {
+				return flow
+			}
+		}
+	}
+}
+
+// pushRelabel computes a maximum flow from s to t using the push-relabel
+// algorithm, it discharges the active vertex with the highest label first
+// and relabels the vertices above a label without vertices, a gap, to
+// more than n at once, as they can't reach t anymore.
+// The labels go up to 2n-1, so the excess that can't reach t
+// goes back to s.
+func (nw *flowNetwork[T]) pushRelabel(s, t int) T {
+	n := nw.order()
+	height := make([]int, n)
+	excess := make([]T, n)
+	next := make([]int, n) // next[v] is the next arc from v to try
+	count := make([]int, 2*n+1)
+	// active[h] has the vertices with excess at the label h,
+	// or that were relabeled since.
+	active := make([][]int, 2*n+1)
+	highest := 0
+	activate := func(v int) {
+		active[height[v]] = append(active[height[v]], v)
+		highest = max(highest, height[v])
+	}
+
+	// No flow is greater than the sum of the finite capacities, as
+	// there is no path of infinite capacity, so it bounds the flow
+	// pushed along the arcs of infinite capacity.
+	inf := InfFor[T]()
+	var bound T
+	for _, c := range nw.orig {
+		if c != inf {
+			bound = addInf(bound, c)
+		}
+	}
+
+	copy(next, nw.start[:n])
+	height[s] = n
+	count[0], count[n] = n-1, 1
+	for a := nw.start[s]; a < nw.start[s+1]; a++ {
+		w, d := nw.head[a], min(nw.cap[a], bound)
+		if !(d > nw.eps) {
+			continue
+		}
+		nw.push(a, d)
+		if w != t && w != s && !(excess[w] > nw.eps) {
+			activate(w)
+		}
+		excess[w] = addInf(excess[w], d)
+	}
+
+	relabel := func(v int) {
+		h := 2*n - 1
+		for a := nw.start[v]; a < nw.start[v+1]; a++ {
+			if nw.cap[a] > nw.eps {
+				h = min(h, height[nw.head[a]]+1)
+			}
+		}
+		old := height[v]
+		count[old]--
+		if count[old] == 0 && old < n {
+			// Gap heuristic.
+			for u := range n {
+				if hu := height[u]; old < hu && hu < n {
+					count[hu]--
+					height[u] = n + 1
+					count[n+1]++
+					next[u] = nw.start[u]
+					if excess[u] > nw.eps && u != t {
+						activate(u)
+					}
+				}
+			}
+			h = max(h, n+1)
+		}
+		height[v] = h
+		count[h]++
+		next[v] = nw.start[v]
+	}
+
+	for highest >= 0 {
+		vs := active[highest]
+		if len(vs) == 0 {
+			highest--
+			continue
+		}
+		v := vs[len(vs)-1]
+		active[highest] = vs[:len(vs)-1]
+		if height[v] != highest || !(excess[v] > nw.eps) {
+			continue
+		}
+		// Discharge v.
+		for excess[v] > nw.eps {
+			if next[v] == nw.start[v+1] {
+				if height[v] >= 2*n-1 {
+					// No arc has capacity above eps, the excess
+					// left is rounding errors, treat it as 0.
+					break
+				}
+				relabel(v)
+				continue
+			}
+			a := next[v]
+			w := nw.head[a]
+			if !(nw.cap[a] > nw.eps) || height[v] != height[w]+1 {
+				next[v]++
+				continue
+			}
+			d := min(excess[v], nw.cap[a])
+			nw.push(a, d)
+			excess[v] -= d
+			if w != t && w != s && !(excess[w] > nw.eps) {
+				activate(w)
+			}
+			excess[w] = addInf(excess[w], d)
+		}
+	}
+	return excess[t]
+}
@@ -1,19 +1,14 @@
 package grafo
 
 import (
-	"bytes"
 	"iter"
 	"math/rand/v2"
-	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/rschio/grafo/internal/encoding/dot"
 	"github.com/rschio/graph"
 )
 
-func itoa64(i int64) string { return strconv.FormatInt(i, 10) }
-
 func FuzzStrongComponents(f *testing.F) {
 	f.Add(uint(10), uint(20), uint64(0), uint64(1))
 	f.Fuzz(func(t *testing.T, VV, EE uint, seed1, seed2 uint64) {
@@ -28,12 +23,7 @@ func FuzzStrongComponents(f *testing.F) {
 		sortComponents(comps2)
 
 		if !cmp.Equal(comps1, comps2) {
-			var buf bytes.Buffer
-			enc := dot.NewEncoder(&buf, itoa64)
-			if err := enc.Encode(g); err != nil {
-				t.Errorf("failed to DOT: %v", err)
-			}
-			t.Errorf("V=%d E=%d\nGraph=[%s]\ncomps1=%v\ncomps2=%v", V, E, buf.String(), comps1, comps2)
+			t.Errorf("V=%d E=%d\nGraph=%s\ncomps1=%v\ncomps2=%v", V, E, String(g), comps1, comps2)
 		}
 	})
 }
@@ -60,12 +50,37 @@ func FuzzShortestPaths(f *testing.F) {
 		_, dist2, _ := BellmanFord(g, v)
 
 		if diff := cmp.Diff(dist1, dist2); diff != "" {
-			var buf bytes.Buffer
-			enc := dot.NewEncoder(&buf, itoa64)
-			if err := enc.Encode(g); err != nil {
-				t.Errorf("failed to DOT: %v", err)
+			t.Errorf("V=%d E=%d maxValue=%d v=%d\nGraph=%s\ndiff=%v", V, E, maxValue, v, String(g), diff)
+		}
+	})
+}
+
+func FuzzDeltaStepping(f *testing.F) {
+	f.Add(uint(10), uint(20), InfFor[int64](), int64(10), uint(4), uint64(0), uint64(1))
+	f.Fuzz(func(t *testing.T, VV, EE uint, maxValue, delta int64, workers uint, seed1, seed2 uint64) {
+		V := int(VV%500 + 1) // Use a small V to test.
+		E := int(EE % uint(V*V))
+		if maxValue <= 0 {
+			maxValue = -maxValue
+			if maxValue == 0 {
+				maxValue = 1
 			}
-			t.Errorf("V=%d E=%d maxValue=%d v=%d\nGraph=[%s]\ndiff=%v", V, E, maxValue, v, buf.String(), diff)
+		}
+		if delta <= 0 {
+			delta = 1
+		}
+		rnd := rand.New(rand.NewPCG(seed1, seed2))
+		weightFn := func() int64 {
+			return rnd.Int64N(maxValue)
+		}
+		g := generateRandomWithRand(V, E, weightFn, rnd)
+		v := rnd.IntN(V)
+
+		_, dist1 := ShortestPaths(g, v)
+		_, dist2 := DeltaStepping(g, v, delta, int(workers%16))
+
+		if diff := cmp.Diff(dist1, dist2); diff != "" {
+			t.Errorf("V=%d E=%d maxValue=%d delta=%d v=%d\nGraph=%s\ndiff=%v", V, E, maxValue, delta, v, String(g), diff)
 		}
 	})
 }
@@ -90,12 +105,7 @@ func FuzzDFS(f *testing.F) {
 
 			path = append(path, e1)
 			if diff := cmp.Diff(e1, e2); diff != "" || ok1 != ok2 {
-				var buf bytes.Buffer
-				enc := dot.NewEncoder(&buf, itoa64)
-				if err := enc.Encode(g); err != nil {
-					t.Errorf("failed to DOT: %v", err)
-				}
-				t.Fatalf("ok1 %v ok2 %v diff: %s\npath[%v]\n%s", ok1, ok2, diff, path, buf.String())
+				t.Fatalf("ok1 %v ok2 %v diff: %s\npath[%v]\n%s", ok1, ok2, diff, path, String(g))
 			}
 			if ok1 == false {
 				break
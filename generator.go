@@ -35,7 +35,7 @@ func generateRandomEdges[T int | int64](V, E int, maxWeight T) *Mutable[T] {
 	return g
 }
 
-func generateRandom(V, E, maxWeight int) *Mutable[int] {
+func generateRandom(V, E, maxWeight int, rnd *rand.Rand) *Mutable[int] {
 	if E > V*(V-1) {
 		panic("GenerateRandom does not generate self-loops or parallel edges, but got: E > V * (V - 1)")
 	}
@@ -48,8 +48,8 @@ func generateRandom(V, E, maxWeight int) *Mutable[int] {
 			if i == j {
 				continue
 			}
-			if rand.Float64() < p {
-				g.Add(i, j, rand.N(maxWeight))
+			if rnd.Float64() < p {
+				g.Add(i, j, rnd.IntN(maxWeight))
 			}
 		}
 	}
@@ -1,6 +1,9 @@
 package grafo
 
-import "testing"
+import (
+	"math/rand/v2"
+	"testing"
+)
 
 func TestGenerator(t *testing.T) {
 	V := 10
@@ -31,7 +34,7 @@ func TestGeneratorRandom(t *testing.T) {
 	V := 50
 	E := 500
 	maxWeight := 50
-	g := generateRandom(V, E, maxWeight)
+	g := generateRandom(V, E, maxWeight, rand.New(rand.NewPCG(0, 1)))
 
 	if g.Order() != V {
 		t.Errorf("got %d want %d vertices", g.Order(), V)
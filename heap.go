@@ -84,6 +84,11 @@ func (q *prioQueue[S, E]) Pop() int {
 	return v
 }
 
+// Peek returns the minimum element of the queue without removing it.
+func (q *prioQueue[S, E]) Peek() int {
+	return q.heap[0]
+}
+
 // Contains tells whether v is in the queue.
 func (q *prioQueue[S, E]) Contains(v int) bool {
 	return q.index[v] >= 0
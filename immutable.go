@@ -43,7 +43,7 @@ import (
 type Immutable[T any] struct {
 	// edges[v] is a sorted list of v's neighbors.
 	edges [][]neighbor[T]
-	stats stats
+	stats Stats
 }
 
 type neighbor[T any] struct {
@@ -51,8 +51,8 @@ type neighbor[T any] struct {
 	weight T
 }
 
-// stats holds basic data about an Iterator.
-type stats struct {
+// Stats holds basic data about a graph.
+type Stats struct {
 	Size     int // Number of unique edges.
 	Multi    int // Number of duplicate edges.
 	Loops    int // Number of self-loops.
@@ -96,26 +96,31 @@ func build[T any](g Graph[T], transpose bool) *Immutable[T] {
 			return cmp.Less(e[i].vertex, e[j].vertex)
 		})
 	}
+	h.computeStats()
+	return h
+}
 
-	for v, neighbors := range h.edges {
+// computeStats sets g.stats, the lists of neighbors must be sorted.
+func (g *Immutable[T]) computeStats() {
+	g.stats = Stats{}
+	for v, neighbors := range g.edges {
 		if len(neighbors) == 0 {
-			h.stats.Isolated++
+			g.stats.Isolated++
 		}
 		prev := -1
 		for _, e := range neighbors {
 			w, _ := e.vertex, e.weight
 			if v == w {
-				h.stats.Loops++
+				g.stats.Loops++
 			}
 			if w == prev {
-				h.stats.Multi++
+				g.stats.Multi++
 			} else {
-				h.stats.Size++
+				g.stats.Size++
 				prev = w
 			}
 		}
 	}
-	return h
 }
 
 // EdgesFrom returns an iterator of edges from vertex v.
@@ -145,6 +150,11 @@ func (g *Immutable[T]) Edge(v, w int) bool {
 	return i < n && w == edges[i].vertex
 }
 
+// Stats returns basic data about the graph.
+func (g *Immutable[T]) Stats() Stats {
+	return g.stats
+}
+
 // Degree returns the number of outward directed edges from v.
 func (g *Immutable[T]) Degree(v int) int {
 	return len(g.edges[v])
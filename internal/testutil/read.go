@@ -1,12 +1,14 @@
 package testutil
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 
-	"github.com/rschio/grafo/internal/encoding/simple"
 	"github.com/rschio/grafo/internal/multigraph"
 )
 
@@ -37,15 +39,60 @@ func ReadGraph[T any](
 	return g
 }
 
+// readGraph reads a graph in the format of encoding/simple.
+// That package can't be used here because it imports grafo
+// and testutil is used by grafo's internal tests.
 func readGraph[T any](r io.Reader, parseWeight func(string) (T, error),
 ) (*multigraph.Multigraph[T], error) {
-	g, err := simple.NewDecoder(r, parseWeight).Decode()
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() {
+		return nil, fmt.Errorf("failed to read number of vertices: %v", sc.Err())
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(sc.Text()))
 	if err != nil {
+		return nil, fmt.Errorf("failed to read number of vertices: %w", err)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("negative number of vertices: %d", n)
+	}
+
+	g := multigraph.New[T](n)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("got %d elements in one line, want 3", len(fields))
+		}
+		v, err := parseVertex(fields[0], n)
+		if err != nil {
+			return nil, err
+		}
+		w, err := parseVertex(fields[1], n)
+		if err != nil {
+			return nil, err
+		}
+		weight, err := parseWeight(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		g.Add(v, w, weight)
+	}
+	if err := sc.Err(); err != nil {
 		return nil, err
 	}
-	mg, ok := g.(*multigraph.Multigraph[T])
-	if !ok {
-		return nil, fmt.Errorf("failed to case graph decoded from simple to *Multigraph")
+
+	return g, nil
+}
+
+func parseVertex(s string, n int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v >= n {
+		return 0, fmt.Errorf("vertex %d out of valid range", v)
 	}
-	return mg, nil
+	return v, nil
 }
@@ -0,0 +1,129 @@
+package grafo
+
+import (
+	"iter"
+	"slices"
+)
+
+// KShortestPaths iterates over the k shortest loopless paths from v to w,
+// in non-decreasing order of their lengths, using Yen's algorithm.
+// Each iteration returns a path and its length, there are fewer than
+// k iterations if there aren't k loopless paths from v to w.
+// As in ShortestPath, only edges with non-negative and non-NaN costs
+// are included and the length of a path is inf if it overflows.
+// (inf is +inf for floats and the maximum value for integers).
+//
+// Each path after the first runs ShortestPath once for each of the
+// vertices of the previous path, stop the iteration early when
+// the remaining paths aren't needed.
+func KShortestPaths[T IntegerOrFloat](g Graph[T], v, w, k int) iter.Seq2[[]int, T] {
+	return func(yield func(path []int, dist T) bool) {
+		if k <= 0 {
+			return
+		}
+		path, dist := ShortestPath(g, v, w)
+		if len(path) == 0 {
+			return
+		}
+		paths := [][]int{path}
+		if !yield(slices.Clone(path), dist) {
+			return
+		}
+
+		n := g.Order()
+		ex := &excluded[T]{g: g, vertices: make([]bool, n), edges: make(map[[2]int]bool)}
+		// The candidates for the next path.
+		var candidates [][]int
+		var costs []T
+		for len(paths) < k {
+			prev := paths[len(paths)-1]
+			var rootCost T
+			// The new paths share the root prev[:i+1] with prev
+			// and leave it at the spur vertex prev[i].
+			for i := range len(prev) - 1 {
+				spur, root := prev[i], prev[:i+1]
+				// Exclude the edges leaving the root of the paths
+				// found and the vertices of the root, so the new path
+				// is different and loopless.
+				clear(ex.edges)
+				for _, p := range paths {
+					if len(p) > i+1 && slices.Equal(p[:i+1], root) {
+						ex.edges[[2]int{spur, p[i+1]}] = true
+					}
+				}
+				for _, u := range root[:i] {
+					ex.vertices[u] = true
+				}
+
+				spurPath, spurCost := ShortestPath(ex, spur, w)
+				if len(spurPath) > 0 {
+					p := append(slices.Clip(root[:i]), spurPath...)
+					if !slices.ContainsFunc(candidates, func(c []int) bool { return slices.Equal(c, p) }) {
+						candidates = append(candidates, p)
+						costs = append(costs, addInf(rootCost, spurCost))
+					}
+				}
+
+				for _, u := range root[:i] {
+					ex.vertices[u] = false
+				}
+				rootCost = addInf(rootCost, edgeCost(g, spur, prev[i+1]))
+			}
+
+			if len(candidates) == 0 {
+				return
+			}
+			best := 0
+			for i := range costs {
+				if costs[i] < costs[best] {
+					best = i
+				}
+			}
+			path, dist := candidates[best], costs[best]
+			candidates = slices.Delete(candidates, best, best+1)
+			costs = slices.Delete(costs, best, best+1)
+			paths = append(paths, path)
+			if !yield(slices.Clone(path), dist) {
+				return
+			}
+		}
+	}
+}
+
+// edgeCost returns the lowest non-negative and non-NaN cost
+// of the edges from v to w, or inf if there is none.
+func edgeCost[T IntegerOrFloat](g Graph[T], v, w int) T {
+	cost := InfFor[T]()
+	for x, weight := range g.EdgesFrom(v) {
+		if x == w && !isNaN(weight) && weight >= 0 && weight < cost {
+			cost = weight
+		}
+	}
+	return cost
+}
+
+// excluded is g without the edges in edges and the edges
+// incident to the vertices in vertices.
+type excluded[T any] struct {
+	g        Graph[T]
+	vertices []bool
+	edges    map[[2]int]bool
+}
+
+func (e *excluded[T]) Order() int { return e.g.Order() }
+
+func (e *excluded[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if e.vertices[v] {
+			return
+		}
+		for w, weight := range e.g.EdgesFrom(v) {
+			if e.vertices[w] || e.edges[[2]int{v, w}] {
+				continue
+			}
+			if !yield(w, weight) {
+				return
+			}
+		}
+	}
+}
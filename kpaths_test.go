@@ -0,0 +1,117 @@
+package grafo
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestKShortestPaths(t *testing.T) {
+	// From https://en.wikipedia.org/wiki/Yen%27s_algorithm,
+	// C=0, D=1, E=2, F=3, G=4, H=5.
+	g := NewMutable[int](6)
+	g.Add(0, 1, 3)
+	g.Add(0, 2, 2)
+	g.Add(1, 3, 4)
+	g.Add(2, 1, 1)
+	g.Add(2, 3, 2)
+	g.Add(2, 4, 3)
+	g.Add(3, 4, 2)
+	g.Add(3, 5, 1)
+	g.Add(4, 5, 2)
+
+	var paths [][]int
+	var dists []int
+	for path, dist := range KShortestPaths(g, 0, 5, 3) {
+		paths = append(paths, path)
+		dists = append(dists, dist)
+	}
+	if diff := cmp.Diff(dists, []int{5, 7, 8}); diff != "" {
+		t.Errorf("KShortestPaths->dist %s", diff)
+	}
+	if diff := cmp.Diff(paths[:2], [][]int{{0, 2, 3, 5}, {0, 2, 4, 5}}); diff != "" {
+		t.Errorf("KShortestPaths->path %s", diff)
+	}
+
+	t.Run("same vertex", func(t *testing.T) {
+		var paths [][]int
+		for path := range KShortestPaths(g, 2, 2, 3) {
+			paths = append(paths, path)
+		}
+		if diff := cmp.Diff(paths, [][]int{{2}}); diff != "" {
+			t.Errorf("KShortestPaths %s", diff)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		for path := range KShortestPaths(g, 5, 0, 3) {
+			t.Errorf("KShortestPaths got path %v", path)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		i := 0
+		for range KShortestPaths(g, 0, 5, 10) {
+			if i++; i == 2 {
+				break
+			}
+		}
+	})
+}
+
+func TestKShortestPathsRandom(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 100 {
+		n := 1 + rnd.IntN(7)
+		g := generateRandom(n, rnd.IntN(n*(n-1)+1), 10, rnd)
+		v, w := rnd.IntN(n), rnd.IntN(n)
+		k := 1 + rnd.IntN(10)
+
+		// The lengths of all loopless paths from v to w.
+		var want []int
+		onPath := make([]bool, n)
+		var dfs func(u, dist int)
+		dfs = func(u, dist int) {
+			if u == w {
+				want = append(want, dist)
+				return
+			}
+			onPath[u] = true
+			for x, weight := range g.EdgesFrom(u) {
+				if !onPath[x] {
+					dfs(x, dist+weight)
+				}
+			}
+			onPath[u] = false
+		}
+		dfs(v, 0)
+		slices.Sort(want)
+		want = want[:min(k, len(want))]
+
+		var got []int
+		var paths [][]int
+		for path, dist := range KShortestPaths(g, v, w, k) {
+			if path[0] != v || path[len(path)-1] != w || pathLength(g, path) != dist {
+				t.Fatalf("KShortestPaths(%s, %d, %d) got path %v, want a path of length %d", String(g), v, w, path, dist)
+			}
+			seen := make(map[int]bool)
+			for _, u := range path {
+				if seen[u] {
+					t.Fatalf("KShortestPaths(%s, %d, %d) got path %v with a loop", String(g), v, w, path)
+				}
+				seen[u] = true
+			}
+			if slices.ContainsFunc(paths, func(p []int) bool { return slices.Equal(p, path) }) {
+				t.Fatalf("KShortestPaths(%s, %d, %d) got path %v twice", String(g), v, w, path)
+			}
+			paths = append(paths, path)
+			got = append(got, dist)
+		}
+		if diff := cmp.Diff(got, want, cmpopts.EquateEmpty()); diff != "" {
+			t.Fatalf("KShortestPaths(%s, %d, %d, %d)->dist %s", String(g), v, w, k, diff)
+		}
+	}
+}
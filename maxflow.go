@@ -28,19 +28,95 @@
 
 package grafo
 
-import "golang.org/x/exp/constraints"
+import "iter"
+
+// MaxFlowAlgorithm is an algorithm used by MaxFlow.
+type MaxFlowAlgorithm int
+
+const (
+	// EdmondsKarp is the Edmonds-Karp algorithm on a copy of the graph,
+	// the default algorithm.
+	// The time complexity is O(|E|²⋅|V|).
+	EdmondsKarp MaxFlowAlgorithm = iota
+	// Dinic is Dinic's algorithm on an array-based residual graph.
+	// The time complexity is O(|E|⋅|V|²).
+	Dinic
+	// PushRelabel is the highest-label push-relabel algorithm with the gap
+	// heuristic on an array-based residual graph.
+	// The time complexity is O(|V|²⋅√|E|).
+	PushRelabel
+)
+
+// MaxFlowOption is an option of MaxFlow.
+type MaxFlowOption func(*maxFlowOptions)
+
+type maxFlowOptions struct {
+	algorithm MaxFlowAlgorithm
+	epsilon   float64
+}
+
+func newMaxFlowOptions(opts []MaxFlowOption) maxFlowOptions {
+	var o maxFlowOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMaxFlowAlgorithm sets the algorithm used by MaxFlow.
+func WithMaxFlowAlgorithm(a MaxFlowAlgorithm) MaxFlowOption {
+	return func(o *maxFlowOptions) { o.algorithm = a }
+}
+
+// WithMaxFlowEpsilon sets the tolerance of MaxFlow for floating-point
+// capacities, the residual capacities and the flows up to eps are
+// treated as 0, so the rounding errors don't make paths of tiny
+// capacity. It is 0 by default and ignored for integers.
+func WithMaxFlowEpsilon(eps float64) MaxFlowOption {
+	return func(o *maxFlowOptions) { o.epsilon = eps }
+}
+
+// epsilon returns the tolerance eps for T, 0 for integers.
+func epsilon[T IntegerOrFloat](eps float64) T {
+	if !isFloat[T]() {
+		return 0
+	}
+	return T(eps)
+}
 
 // MaxFlow computes a maximum flow from s to t in a graph
 // with nonnegative edge capacities.
-// The time complexity is O(|E|²⋅|V|), where |E| is the number of edges
-// and |V| the number of vertices in the graph.
-func MaxFlow[T constraints.Integer](g Graph[T], s, t int) (flow T, graph Graph[T]) {
+// The flow is inf if s equals t or if there is a path of edges of
+// infinite capacity from s to t, then the graph has no flow.
+// (inf is +inf for floats and the maximum value for integers).
+// The graph has the flow of each edge, an edge with no flow is omitted.
+//
+// The algorithm is chosen with WithMaxFlowAlgorithm, EdmondsKarp by default.
+// EdmondsKarp keeps only one of the parallel edges of g, the other
+// algorithms add their capacities.
+// The tolerance for floating-point capacities is set with WithMaxFlowEpsilon.
+func MaxFlow[T IntegerOrFloat](g Graph[T], s, t int, opts ...MaxFlowOption) (flow T, graph Graph[T]) {
+	o := newMaxFlowOptions(opts)
+	eps := epsilon[T](o.epsilon)
+	if o.algorithm != EdmondsKarp {
+		nw := newFlowNetwork(g)
+		nw.eps = eps
+		flow, _ = nw.maxFlow(s, t, o.algorithm)
+		return flow, nw.flowGraph(nw.order(), true)
+	}
+
 	// Edmonds-Karp's algorithm
 	inf := InfFor[T]()
 	n := g.Order()
 	prev := make([]int, n)
 	residual := Copy(g)
-	for residualFlow(residual, s, t, prev) && flow < inf {
+	// The net flow between each pair of vertices, as the capacity
+	// minus the residual capacity is NaN for infinite capacities.
+	net := NewMutable[T](n)
+	if infinitePath(g, s, t) {
+		return inf, Sort(net)
+	}
+	for residualFlow(residual, s, t, prev, eps) && flow < inf {
 		pathFlow := inf
 		for v := t; v != s; {
 			u := prev[v]
@@ -49,18 +125,24 @@ func MaxFlow[T constraints.Integer](g Graph[T], s, t int) (flow T, graph Graph[T
 			}
 			v = u
 		}
-		flow += pathFlow
+		flow = addInf(flow, pathFlow)
 		for v := t; v != s; {
 			u := prev[v]
 			residual.Add(u, v, residual.Weight(u, v)-pathFlow)
-			residual.Add(v, u, residual.Weight(v, u)+pathFlow)
+			residual.Add(v, u, addInf(residual.Weight(v, u), pathFlow))
+			if back := net.Weight(v, u); back >= pathFlow {
+				net.Add(v, u, back-pathFlow)
+			} else {
+				net.Delete(v, u)
+				net.Add(u, v, net.Weight(u, v)+pathFlow-back)
+			}
 			v = u
 		}
 	}
 	res := NewMutable[T](n)
 	for v := 0; v < n; v++ {
-		for w, weight := range g.EdgesFrom(v) {
-			if flow := weight - residual.Weight(v, w); flow > 0 {
+		for w := range g.EdgesFrom(v) {
+			if flow := net.Weight(v, w); flow > eps {
 				res.Add(v, w, flow)
 			}
 		}
@@ -68,7 +150,7 @@ func MaxFlow[T constraints.Integer](g Graph[T], s, t int) (flow T, graph Graph[T
 	return flow, Sort(res)
 }
 
-func residualFlow[T constraints.Integer](g *Mutable[T], s, t int, prev []int) bool {
+func residualFlow[T IntegerOrFloat](g *Mutable[T], s, t int, prev []int, eps T) bool {
 	visited := make([]bool, g.Order())
 	prev[s], visited[s] = -1, true
 	queue := newQueue(10)
@@ -77,7 +159,7 @@ func residualFlow[T constraints.Integer](g *Mutable[T], s, t int, prev []int) bo
 	for queue.Len() > 0 {
 		v := queue.Pop()
 		for w, weight := range g.EdgesFrom(v) {
-			if !visited[w] && weight > 0 {
+			if !visited[w] && weight > eps {
 				prev[w] = v
 				visited[w] = true
 				queue.Push(w)
@@ -86,3 +168,82 @@ func residualFlow[T constraints.Integer](g *Mutable[T], s, t int, prev []int) bo
 	}
 	return visited[t]
 }
+
+// infinitePath tells whether there is a path of edges
+// of infinite capacity from s to t.
+func infinitePath[T IntegerOrFloat](g Graph[T], s, t int) bool {
+	inf := InfFor[T]()
+	visited := make([]bool, g.Order())
+	visited[s] = true
+	queue := newQueue(10)
+	queue.Push(s)
+	for queue.Len() > 0 {
+		v := queue.Pop()
+		for w, weight := range g.EdgesFrom(v) {
+			if !visited[w] && weight == inf {
+				visited[w] = true
+				queue.Push(w)
+			}
+		}
+	}
+	return visited[t]
+}
+
+// MultiSourceMaxFlow computes a maximum flow from the sources to the
+// sinks in a graph with nonnegative edge capacities, as the maximum flow
+// from a super-source linked to the sources to a super-sink linked from
+// the sinks, with edges of infinite capacity.
+// The flow is inf if a vertex is both a source and a sink or if there is
+// a path of edges of infinite capacity from a source to a sink, then
+// the graph has no flow.
+// (inf is +inf for floats and the maximum value for integers).
+// The graph has the flow of each edge of g, an edge with no flow is omitted.
+//
+// The options are the same as in MaxFlow, the flow is computed on
+// an array-based residual graph and the parallel edges of g
+// add their capacities.
+func MultiSourceMaxFlow[T IntegerOrFloat](g Graph[T], sources, sinks []int, opts ...MaxFlowOption) (flow T, graph Graph[T]) {
+	o := newMaxFlowOptions(opts)
+	n := g.Order()
+	isSink := make([]bool, n)
+	for _, t := range sinks {
+		isSink[t] = true
+	}
+	nw := newFlowNetwork(&terminals[T]{g: g, sources: sources, isSink: isSink})
+	nw.eps = epsilon[T](o.epsilon)
+	flow, _ = nw.maxFlow(n, n+1, o.algorithm)
+	return flow, nw.flowGraph(n, true)
+}
+
+// terminals is g with a vertex n linked to the sources and a vertex n+1
+// linked from the sinks, by edges of infinite capacity.
+type terminals[T IntegerOrFloat] struct {
+	g       Graph[T]
+	sources []int
+	isSink  []bool
+}
+
+func (g *terminals[T]) Order() int { return g.g.Order() + 2 }
+
+func (g *terminals[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		n, inf := g.g.Order(), InfFor[T]()
+		switch {
+		case v == n:
+			for _, s := range g.sources {
+				if !yield(s, inf) {
+					return
+				}
+			}
+		case v < n:
+			for w, c := range g.g.EdgesFrom(v) {
+				if !yield(w, c) {
+					return
+				}
+			}
+			if g.isSink[v] {
+				yield(n+1, inf)
+			}
+		}
+	}
+}
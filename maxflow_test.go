@@ -2,12 +2,16 @@ package grafo
 
 import (
 	"bytes"
+	"math"
+	"math/rand/v2"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo/internal/multigraph"
 	"github.com/rschio/grafo/internal/testutil"
 	"golang.org/x/tools/txtar"
 )
@@ -19,24 +23,305 @@ func TestMaxFlow(t *testing.T) {
 	}
 	files := archive.Files
 
-	for i := 0; i+1 < len(files); i += 2 {
-		t.Run(files[i].Name, func(t *testing.T) {
-			g := testutil.ReadGraph(t, bytes.NewReader(files[i].Data), strconv.Atoi)
-			source, target, wantFlow, wantGraph := readTxtarAnswer(t, files[i+1])
+	algorithms := []struct {
+		name string
+		a    MaxFlowAlgorithm
+	}{
+		{"EdmondsKarp", EdmondsKarp},
+		{"Dinic", Dinic},
+		{"PushRelabel", PushRelabel},
+	}
+	for _, alg := range algorithms {
+		for i := 0; i+1 < len(files); i += 2 {
+			t.Run(alg.name+"/"+files[i].Name, func(t *testing.T) {
+				g := testutil.ReadGraph(t, bytes.NewReader(files[i].Data), strconv.Atoi)
+				source, target, wantFlow, wantGraph := readTxtarAnswer(t, files[i+1])
+
+				flow, res := MaxFlow(g, source, target, WithMaxFlowAlgorithm(alg.a))
+				if flow != wantFlow {
+					t.Errorf("got %v flow want %v", flow, wantFlow)
+				}
+
+				if alg.a != EdmondsKarp {
+					// The flow of each edge may differ.
+					testFlow(t, g, source, target, flow, res, 0)
+					return
+				}
+				if wantGraph == "-" { // Skip graph string test.
+					return
+				}
+				if diff := cmp.Diff(String(res), wantGraph); diff != "" {
+					t.Errorf("MaxFlow(%d, %d) = %s", source, target, diff)
+				}
+			})
+		}
+	}
 
-			flow, res := MaxFlow(g, source, target)
-			if flow != wantFlow {
-				t.Errorf("got %v flow want %v", flow, wantFlow)
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 2 + rnd.IntN(100)
+			g := generateRandom(n, rnd.IntN(min(5*n, n*(n-1))), 50, rnd)
+			s, tt := rnd.IntN(n), rnd.IntN(n)
+			if s == tt {
+				continue
+			}
+			want, _ := MaxFlow(g, s, tt)
+			for _, a := range []MaxFlowAlgorithm{Dinic, PushRelabel} {
+				flow, res := MaxFlow(g, s, tt, WithMaxFlowAlgorithm(a))
+				if flow != want {
+					t.Fatalf("MaxFlow(%d, %d, %v) = %d, want %d", s, tt, a, flow, want)
+				}
+				testFlow(t, g, s, tt, flow, res, 0)
 			}
+		}
+	})
 
-			if wantGraph == "-" { // Skip graph string test.
-				return
+	t.Run("unsigned", func(t *testing.T) {
+		g := NewMutable[uint8](4)
+		g.Add(0, 1, 3)
+		g.Add(0, 2, 2)
+		g.Add(1, 2, 3)
+		g.Add(2, 1, 2)
+		g.Add(1, 3, 2)
+		g.Add(2, 3, 3)
+		for _, a := range []MaxFlowAlgorithm{EdmondsKarp, Dinic, PushRelabel} {
+			flow, res := MaxFlow(g, 0, 3, WithMaxFlowAlgorithm(a))
+			if flow != 5 {
+				t.Errorf("MaxFlow(%v) = %d, want 5", a, flow)
 			}
-			if diff := cmp.Diff(String(res), wantGraph); diff != "" {
-				t.Errorf("MaxFlow(%d, %d) = %s", source, target, diff)
+			testFlow(t, g, 0, 3, flow, res, 0)
+		}
+	})
+
+	t.Run("parallel edges", func(t *testing.T) {
+		g := multigraph.New[int](3)
+		g.Add(0, 1, 2)
+		g.Add(0, 1, 3)
+		g.Add(1, 2, 4)
+		g.Add(1, 1, 7)
+		for _, a := range []MaxFlowAlgorithm{Dinic, PushRelabel} {
+			flow, res := MaxFlow(g, 0, 2, WithMaxFlowAlgorithm(a))
+			if flow != 4 {
+				t.Errorf("MaxFlow(%v) = %d, want 4", a, flow)
+			}
+			if diff := cmp.Diff(String(res), "3 [(0 1):4 (1 2):4]"); diff != "" {
+				t.Errorf("MaxFlow(%v) graph %s", a, diff)
 			}
-		})
+		}
+	})
+}
+
+// testFlow checks that res is a flow of value flow from s to t
+// within the capacities of g, up to tol.
+func testFlow[T IntegerOrFloat](t *testing.T, g Graph[T], s, tt int, flow T, res Graph[T], tol T) {
+	t.Helper()
+	n := g.Order()
+	capacity := make(map[[2]int]T)
+	for v := range n {
+		for w, c := range g.EdgesFrom(v) {
+			capacity[[2]int{v, w}] += c
+		}
 	}
+	near := func(a, b T) bool { return a <= b+tol && b <= a+tol }
+	balance := make([]T, n)
+	for v := range n {
+		for w, f := range res.EdgesFrom(v) {
+			if f > capacity[[2]int{v, w}]+tol {
+				t.Fatalf("flow %v of (%d %d) exceeds its capacity", f, v, w)
+			}
+			balance[v] -= f
+			balance[w] += f
+		}
+	}
+	for v, b := range balance {
+		switch {
+		case v == s && s != tt && !near(b, -flow):
+			t.Fatalf("flow from the source %d is %v, want %v", v, -b, flow)
+		case v == tt && s != tt && !near(b, flow):
+			t.Fatalf("flow to the target %d is %v, want %v", v, b, flow)
+		case v != s && v != tt && !near(b, 0):
+			t.Fatalf("flow is not conserved at %d: %v", v, b)
+		}
+	}
+}
+
+func TestMaxFlowFloat(t *testing.T) {
+	const eps = 1e-9
+	algorithms := []MaxFlowAlgorithm{EdmondsKarp, Dinic, PushRelabel}
+
+	t.Run("txtar", func(t *testing.T) {
+		archive, err := txtar.ParseFile(filepath.Join("testdata", "maxflow.txtar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := archive.Files
+		// The capacities are tenths, which floats can't represent.
+		parse := func(s string) (float64, error) {
+			x, err := strconv.ParseFloat(s, 64)
+			return x / 10, err
+		}
+		for _, a := range algorithms {
+			for i := 0; i+1 < len(files); i += 2 {
+				g := testutil.ReadGraph(t, bytes.NewReader(files[i].Data), parse)
+				source, target, wantFlow, _ := readTxtarAnswer(t, files[i+1])
+				want := float64(wantFlow) / 10
+				if source == target {
+					want = math.Inf(1)
+				}
+
+				flow, res := MaxFlow(g, source, target, WithMaxFlowAlgorithm(a), WithMaxFlowEpsilon(eps))
+				if math.Abs(flow-want) > 1e-6 && flow != want {
+					t.Errorf("%s: MaxFlow(%d) = %v, want %v", files[i].Name, a, flow, want)
+				}
+				testFlow(t, g, source, target, flow, res, 1e-6)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 2 + rnd.IntN(100)
+			g := NewMutable[float64](n)
+			for range rnd.IntN(5 * n) {
+				g.Add(rnd.IntN(n), rnd.IntN(n), rnd.Float64()*10)
+			}
+			s, tt := rnd.IntN(n), rnd.IntN(n)
+			if s == tt {
+				continue
+			}
+			want, _ := MaxFlow(g, s, tt, WithMaxFlowEpsilon(eps))
+			for _, a := range algorithms[1:] {
+				flow, res := MaxFlow(g, s, tt, WithMaxFlowAlgorithm(a), WithMaxFlowEpsilon(eps))
+				if math.Abs(flow-want) > 1e-6 {
+					t.Fatalf("MaxFlow(%d, %d, %d) = %v, want %v", s, tt, a, flow, want)
+				}
+				testFlow(t, g, s, tt, flow, res, 1e-6)
+			}
+		}
+	})
+
+	t.Run("epsilon", func(t *testing.T) {
+		g := NewMutable[float64](3)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, 1e-12)
+		for _, a := range algorithms {
+			if flow, _ := MaxFlow(g, 0, 2, WithMaxFlowAlgorithm(a)); flow != 1e-12 {
+				t.Errorf("MaxFlow(%d) = %v, want 1e-12", a, flow)
+			}
+			if flow, _ := MaxFlow(g, 0, 2, WithMaxFlowAlgorithm(a), WithMaxFlowEpsilon(eps)); flow != 0 {
+				t.Errorf("MaxFlow(%d) with epsilon = %v, want 0", a, flow)
+			}
+		}
+	})
+
+	t.Run("leftover excess", func(t *testing.T) {
+		// The vertex 2 keeps excess above eps after the rounding
+		// errors, but none of its arcs has capacity above eps.
+		g := NewMutable[float64](5)
+		g.Add(0, 2, 0.76)
+		g.Add(0, 4, 0.89)
+		g.Add(2, 3, 0.87)
+		g.Add(4, 2, 0.57)
+		for _, a := range algorithms {
+			if flow, _ := MaxFlow(g, 0, 1, WithMaxFlowAlgorithm(a), WithMaxFlowEpsilon(0.3)); flow != 0 {
+				t.Errorf("MaxFlow(%d) = %v, want 0", a, flow)
+			}
+		}
+	})
+
+	t.Run("infinite", func(t *testing.T) {
+		inf := math.Inf(1)
+		g := NewMutable[float64](4)
+		g.Add(0, 1, inf)
+		g.Add(1, 2, 2.5)
+		g.Add(0, 2, inf)
+		g.Add(2, 3, inf)
+		for _, a := range algorithms {
+			if flow, _ := MaxFlow(g, 0, 1, WithMaxFlowAlgorithm(a)); flow != inf {
+				t.Errorf("MaxFlow(%d) = %v, want +Inf", a, flow)
+			}
+			flow, res := MaxFlow(g, 1, 3, WithMaxFlowAlgorithm(a))
+			if flow != 2.5 {
+				t.Errorf("MaxFlow(%d) = %v, want 2.5", a, flow)
+			}
+			testFlow(t, g, 1, 3, flow, res, 0)
+		}
+	})
+}
+
+func TestMultiSourceMaxFlow(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 2 + rnd.IntN(100)
+			g := generateRandom(n, rnd.IntN(min(5*n, n*(n-1))), 50, rnd)
+			sources, sinks := rnd.Perm(n)[:1+rnd.IntN(n/2+1)], []int{}
+			for v := range n {
+				if !slices.Contains(sources, v) && rnd.IntN(3) == 0 {
+					sinks = append(sinks, v)
+				}
+			}
+
+			// The super-source n and the super-sink n+1.
+			h := NewMutable[int](n + 2)
+			for v := range n {
+				for w, c := range g.EdgesFrom(v) {
+					h.Add(v, w, c)
+				}
+			}
+			for _, v := range sources {
+				h.Add(n, v, InfFor[int]())
+			}
+			for _, v := range sinks {
+				h.Add(v, n+1, InfFor[int]())
+			}
+			want, _ := MaxFlow(h, n, n+1)
+
+			for _, a := range []MaxFlowAlgorithm{EdmondsKarp, Dinic, PushRelabel} {
+				flow, res := MultiSourceMaxFlow(g, sources, sinks, WithMaxFlowAlgorithm(a))
+				if flow != want {
+					t.Fatalf("MultiSourceMaxFlow(%v, %v, %d) = %d, want %d", sources, sinks, a, flow, want)
+				}
+				if res.Order() != n {
+					t.Fatalf("MultiSourceMaxFlow graph has %d vertices, want %d", res.Order(), n)
+				}
+				// Check the flow with the edges of the super vertices.
+				flows := NewMutable[int](n + 2)
+				balance := make([]int, n)
+				for _, e := range edgeList(res) {
+					flows.Add(e.V, e.W, e.Weight)
+					balance[e.V] -= e.Weight
+					balance[e.W] += e.Weight
+				}
+				for _, v := range sources {
+					flows.Add(n, v, -balance[v])
+				}
+				for _, v := range sinks {
+					flows.Add(v, n+1, balance[v])
+				}
+				testFlow(t, Graph[int](h), n, n+1, flow, Graph[int](flows), 0)
+			}
+		}
+	})
+
+	t.Run("overlap", func(t *testing.T) {
+		g := NewMutable[float64](3)
+		g.Add(0, 1, 1)
+		g.Add(1, 2, 1)
+		flow, res := MultiSourceMaxFlow(g, []int{0, 1}, []int{1, 2})
+		if flow != math.Inf(1) || len(edgeList(res)) != 0 {
+			t.Errorf("MultiSourceMaxFlow = %v, %v, want +Inf and no flow", flow, String(res))
+		}
+		flow, res = MultiSourceMaxFlow(g, []int{0}, []int{1, 2})
+		if flow != 1 {
+			t.Errorf("MultiSourceMaxFlow = %v, want 1", flow)
+		}
+		if diff := cmp.Diff(String(res), "3 [(0 1):1]"); diff != "" {
+			t.Errorf("MultiSourceMaxFlow graph %s", diff)
+		}
+	})
 }
 
 func readTxtarAnswer(t testing.TB, f txtar.File) (source, target, flow int, graph string) {
@@ -0,0 +1,612 @@
+package grafo
+
+import (
+	"errors"
+	"iter"
+	"math"
+	"slices"
+
+	"golang.org/x/exp/constraints"
+)
+
+// ErrInfeasible is returned by MinCostFlow if no flow
+// satisfies the supplies.
+var ErrInfeasible = errors.New("grafo: infeasible flow")
+
+// ErrUnbounded is returned by MinCostFlow if an edge has a negative
+// cost and an infinite capacity, the cost of the flow may be unbounded.
+var ErrUnbounded = errors.New("grafo: unbounded flow cost")
+
+// ErrCostOverflow is returned by MinCostFlow with NetworkSimplex if the
+// costs are too large for the integer type to run the algorithm.
+var ErrCostOverflow = errors.New("grafo: flow cost overflow")
+
+// CapacityCost is the weight of an edge in a flow network with costs,
+// the edge carries at most Capacity units of flow at Cost per unit.
+type CapacityCost[T IntegerOrFloat] struct {
+	Capacity, Cost T
+}
+
+// MinCostFlowAlgorithm is an algorithm used by MinCostFlow.
+type MinCostFlowAlgorithm int
+
+const (
+	// SuccessiveShortestPaths pushes flow along the shortest paths
+	// in the residual graph, found by Dijkstra's algorithm with
+	// potentials, the default algorithm.
+	// The time complexity is O(F⋅(|E| + |V|)⋅log|V|), where F is
+	// the total supply.
+	SuccessiveShortestPaths MinCostFlowAlgorithm = iota
+	// NetworkSimplex is the primal network simplex algorithm, faster
+	// when the supplies are large.
+	NetworkSimplex
+)
+
+// MinCostFlowOption is an option of MinCostFlow.
+type MinCostFlowOption func(*minCostFlowOptions)
+
+type minCostFlowOptions struct {
+	algorithm MinCostFlowAlgorithm
+	epsilon   float64
+}
+
+// WithMinCostFlowAlgorithm sets the algorithm used by MinCostFlow.
+func WithMinCostFlowAlgorithm(a MinCostFlowAlgorithm) MinCostFlowOption {
+	return func(o *minCostFlowOptions) { o.algorithm = a }
+}
+
+// WithMinCostFlowEpsilon sets the tolerance of MinCostFlow for
+// floating-point capacities and supplies, the residual capacities,
+// the flows and the imbalances up to eps are treated as 0, so the
+// rounding errors don't make a flow infeasible. It is 0 by default
+// and ignored for integers.
+func WithMinCostFlowEpsilon(eps float64) MinCostFlowOption {
+	return func(o *minCostFlowOptions) { o.epsilon = eps }
+}
+
+// MinCostFlow computes a flow of minimum cost in a graph where each
+// edge has a capacity and a cost, such that supply[v] units of flow
+// leave each vertex v, a negative supply is a demand.
+// A nil supply finds a minimum cost circulation, where the flow
+// that enters each vertex leaves it.
+// The number cost is the total cost of the flow and the graph has
+// the flow between each pair of vertices, the pairs with no flow
+// are omitted. MinCostFlow panics if supply isn't nil and its length
+// isn't the number of vertices.
+//
+// The edges may have negative costs, but then they must have finite
+// capacities, otherwise ErrUnbounded is returned. The self-loops and
+// the edges without a positive capacity are skipped.
+// If no flow satisfies the supplies, ErrInfeasible is returned.
+// NetworkSimplex returns ErrCostOverflow if the integer costs are too
+// large, about the maximum value of T divided by 4⋅|V|.
+//
+// The algorithm is chosen with WithMinCostFlowAlgorithm,
+// SuccessiveShortestPaths by default.
+// The tolerance for floating-point values is set with WithMinCostFlowEpsilon.
+func MinCostFlow[T constraints.Signed | constraints.Float](g Graph[CapacityCost[T]], supply []T, opts ...MinCostFlowOption) (cost T, graph Graph[T], err error) {
+	n := g.Order()
+	if supply == nil {
+		supply = make([]T, n)
+	}
+	if len(supply) != n {
+		panic("grafo: MinCostFlow supply length isn't the number of vertices")
+	}
+	var o minCostFlowOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	eps := epsilon[T](o.epsilon)
+	var total T
+	for _, b := range supply {
+		total += b
+	}
+	if total > eps || total < -eps {
+		return 0, nil, ErrInfeasible
+	}
+	inf := InfFor[T]()
+	for v := range n {
+		for w, e := range g.EdgesFrom(v) {
+			if v != w && e.Capacity > 0 && e.Cost < 0 && e.Capacity == inf {
+				return 0, nil, ErrUnbounded
+			}
+		}
+	}
+
+	var nw *flowNetwork[T]
+	switch o.algorithm {
+	case NetworkSimplex:
+		nw = buildFlowNetwork(g, capacityCost[T])
+		nw.eps = eps
+		cost, err = nw.networkSimplex(supply)
+	default:
+		cost, nw, err = successiveShortestPaths(g, supply, eps)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return cost, nw.flowGraph(n, false), nil
+}
+
+// MinCostMaxFlow computes a maximum flow from s to t of minimum cost,
+// in a graph where each edge has a capacity and a cost.
+// The results are the same as in MinCostFlow with the supply of s
+// equal to the maximum flow and the demand of t equal to it,
+// and the errors are only the ones about the costs.
+// If s equals t or there is a path of edges of infinite capacity from s
+// to t, the flow is inf and the graph is a minimum cost circulation.
+// (inf is +inf for floats and the maximum value for integers).
+func MinCostMaxFlow[T constraints.Signed | constraints.Float](g Graph[CapacityCost[T]], s, t int, opts ...MinCostFlowOption) (flow, cost T, graph Graph[T], err error) {
+	supply := make([]T, g.Order())
+	flow, finite := newFlowNetwork(capacities[T]{g}).maxFlow(s, t, Dinic)
+	if finite {
+		supply[s], supply[t] = flow, -flow
+	}
+	cost, graph, err = MinCostFlow(g, supply, opts...)
+	return flow, cost, graph, err
+}
+
+func capacityCost[T IntegerOrFloat](e CapacityCost[T]) (capacity, cost T) {
+	return e.Capacity, e.Cost
+}
+
+// capacities is the graph of the capacities of the edges of g.
+type capacities[T IntegerOrFloat] struct {
+	g Graph[CapacityCost[T]]
+}
+
+func (c capacities[T]) Order() int { return c.g.Order() }
+
+func (c capacities[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for w, e := range c.g.EdgesFrom(v) {
+			if !yield(w, e.Capacity) {
+				return
+			}
+		}
+	}
+}
+
+// successiveShortestPaths computes a flow of minimum cost that satisfies
+// the supplies, using the successive shortest paths algorithm on the
+// residual graph of g with a source n linked to the vertices with
+// supplies and a sink n+1 linked from the vertices with demands.
+//
+// The initial potentials are the distances found by BellmanFord from a
+// vertex linked to all the others, so the reduced costs are non-negative.
+// BellmanFord fails if the edges with capacity have a negative cycle,
+// which a flow of minimum cost may use, then the edges with negative
+// costs are saturated first instead, so all the arcs with capacity have
+// non-negative costs and the potentials start at 0.
+func successiveShortestPaths[T IntegerOrFloat](g Graph[CapacityCost[T]], supply []T, eps T) (cost T, nw *flowNetwork[T], err error) {
+	n := g.Order()
+	pi := make([]T, n+2)
+	_, dist, ok := BellmanFord[T](&costs[T]{g: g}, n)
+	if ok {
+		copy(pi, dist)
+		// The arcs from the source and to the sink cost 0.
+		pi[n] = slices.Min(dist)
+		pi[n+1] = pi[n]
+	} else {
+		supply = append([]T(nil), supply...)
+		for v := range n {
+			for w, e := range g.EdgesFrom(v) {
+				if v != w && e.Capacity > 0 && e.Cost < 0 {
+					supply[v] -= e.Capacity
+					supply[w] += e.Capacity
+				}
+			}
+		}
+	}
+	nw = buildFlowNetwork(&supplied[T]{g: g, supply: supply}, capacityCost[T])
+	nw.eps = eps
+	if !ok {
+		for a := range nw.head {
+			if nw.orig[a] > 0 && nw.cost[a] < 0 {
+				cost += nw.orig[a] * nw.cost[a]
+				nw.push(a, nw.orig[a])
+			}
+		}
+	}
+	var total T
+	for _, b := range supply {
+		total += max(b, 0)
+	}
+
+	s, t := n, n+1
+	res := &reducedResidual[T]{nw: nw, pi: pi}
+	inf := InfFor[T]()
+	var flow T
+	for total-flow > eps {
+		parent, dist := ShortestPaths(res, s)
+		if dist[t] == inf && parent[t] == -1 {
+			break
+		}
+		for v, d := range dist {
+			if d != inf {
+				res.pi[v] += d
+			}
+		}
+
+		// Find the arcs of the path and its capacity.
+		path := []int{}
+		d := inf
+		for w := t; w != s; w = parent[w] {
+			a := res.arc(parent[w], w)
+			path = append(path, a)
+			d = min(d, nw.cap[a])
+		}
+		for _, a := range path {
+			nw.push(a, d)
+			cost += d * nw.cost[a]
+		}
+		flow += d
+	}
+	if total-flow > eps {
+		return 0, nil, ErrInfeasible
+	}
+	return cost, nw, nil
+}
+
+// costs is the graph of the costs of the edges of g with a positive
+// capacity, with a vertex n linked to all the others at cost 0.
+type costs[T IntegerOrFloat] struct {
+	g Graph[CapacityCost[T]]
+}
+
+func (c *costs[T]) Order() int { return c.g.Order() + 1 }
+
+func (c *costs[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		n := c.g.Order()
+		if v == n {
+			for w := range n {
+				if !yield(w, 0) {
+					return
+				}
+			}
+			return
+		}
+		for w, e := range c.g.EdgesFrom(v) {
+			if v != w && e.Capacity > 0 && !yield(w, e.Cost) {
+				return
+			}
+		}
+	}
+}
+
+// supplied is g with a vertex n linked to each vertex v with a positive
+// supply, with capacity supply[v], and a vertex n+1 linked from each
+// vertex v with a negative supply, with capacity -supply[v].
+type supplied[T IntegerOrFloat] struct {
+	g      Graph[CapacityCost[T]]
+	supply []T
+}
+
+func (s *supplied[T]) Order() int { return len(s.supply) + 2 }
+
+func (s *supplied[T]) EdgesFrom(v int) iter.Seq2[int, CapacityCost[T]] {
+	return func(yield func(int, CapacityCost[T]) bool) {
+		n := len(s.supply)
+		switch {
+		case v == n:
+			for w, b := range s.supply {
+				if b > 0 && !yield(w, CapacityCost[T]{Capacity: b}) {
+					return
+				}
+			}
+		case v < n:
+			for w, e := range s.g.EdgesFrom(v) {
+				if !yield(w, e) {
+					return
+				}
+			}
+			if b := s.supply[v]; b < 0 {
+				yield(n+1, CapacityCost[T]{Capacity: -b})
+			}
+		}
+	}
+}
+
+// reducedResidual is the graph of the arcs with capacity of nw,
+// weighted by their costs reduced by the potentials pi.
+type reducedResidual[T IntegerOrFloat] struct {
+	nw *flowNetwork[T]
+	pi []T
+}
+
+func (r *reducedResidual[T]) Order() int { return r.nw.order() }
+
+func (r *reducedResidual[T]) EdgesFrom(v int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for a := r.nw.start[v]; a < r.nw.start[v+1]; a++ {
+			if !(r.nw.cap[a] > r.nw.eps) {
+				continue
+			}
+			if !yield(r.nw.head[a], r.reduced(a)) {
+				return
+			}
+		}
+	}
+}
+
+// reduced returns the reduced cost of the arc a, the rounding errors
+// of the floats that make it negative are discarded.
+func (r *reducedResidual[T]) reduced(a int) T {
+	v, w := r.nw.head[r.nw.rev[a]], r.nw.head[a]
+	return max(r.nw.cost[a]+r.pi[v]-r.pi[w], 0)
+}
+
+// arc returns the arc with capacity from v to w of lowest reduced cost.
+func (r *reducedResidual[T]) arc(v, w int) int {
+	best := -1
+	for a := r.nw.start[v]; a < r.nw.start[v+1]; a++ {
+		if r.nw.head[a] == w && r.nw.cap[a] > r.nw.eps && (best == -1 || r.reduced(a) < r.reduced(best)) {
+			best = a
+		}
+	}
+	return best
+}
+
+// The states of the arcs in the network simplex.
+const (
+	simplexUpper int8 = -1 // the flow is the capacity
+	simplexTree  int8 = 0  // the arc is in the spanning tree
+	simplexLower int8 = 1  // there is no flow
+)
+
+// networkSimplex computes a flow of minimum cost that satisfies the
+// supplies, using the primal network simplex algorithm.
+//
+// The spanning tree starts with an artificial arc of high cost between
+// each vertex and an artificial root, carrying its supply. Each pivot
+// adds an arc of negative reduced cost to the tree, pushes flow along
+// the cycle it makes and removes the last blocking arc of the cycle,
+// which keeps the tree strongly feasible. The flow is feasible if no
+// artificial arc carries flow at the end.
+func (nw *flowNetwork[T]) networkSimplex(supply []T) (cost T, err error) {
+	n := nw.order()
+	var ids []int // the arcs of nw, without the reverse arcs
+	for a := range nw.head {
+		if nw.orig[a] > 0 {
+			ids = append(ids, a)
+		}
+	}
+	m := len(ids)
+	root := n
+	inf := InfFor[T]()
+
+	// The arcs m+v are the artificial arcs between v and the root.
+	tail, head := make([]int, m+n), make([]int, m+n)
+	capacity, arcCost, flow := make([]T, m+n), make([]T, m+n), make([]T, m+n)
+	state := make([]int8, m+n)
+	var maxCost T
+	for i, a := range ids {
+		tail[i], head[i] = nw.head[nw.rev[a]], nw.head[a]
+		capacity[i], arcCost[i], state[i] = nw.orig[a], nw.cost[a], simplexLower
+		maxCost = max(maxCost, nw.cost[a], -nw.cost[a])
+	}
+	// The potentials and the reduced costs stay below 4 times the
+	// artificial cost, it must be small enough for them not to overflow.
+	if limit := inf / 4; !isFloat[T]() && (uint64(n+1) > uint64(limit) || maxCost >= limit/T(n+1)) {
+		return 0, ErrCostOverflow
+	}
+	artCost := (maxCost + 1) * T(n+1)
+
+	parent := make([]int, n+1) // parent[v] is the parent of v in the tree
+	pred := make([]int, n+1)   // pred[v] is the arc between v and parent[v]
+	depth := make([]int, n+1)  // depth[v] is the depth of v in the tree
+	pi := make([]T, n+1)       // the potentials, the tree arcs have reduced cost 0
+	// The children of v in the tree are child[v], next[child[v]], ...
+	// and prev links them backwards, -1 ends the lists.
+	child, next, prev := make([]int, n+1), make([]int, n+1), make([]int, n+1)
+	link := func(v, p int) {
+		next[v], prev[v] = child[p], -1
+		if child[p] != -1 {
+			prev[child[p]] = v
+		}
+		child[p] = v
+	}
+	unlink := func(v, p int) {
+		if prev[v] != -1 {
+			next[prev[v]] = next[v]
+		} else {
+			child[p] = next[v]
+		}
+		if next[v] != -1 {
+			prev[next[v]] = prev[v]
+		}
+	}
+	parent[root], pred[root] = -1, -1
+	child[root] = -1
+	for v := range n {
+		a := m + v
+		// The arcs without flow point away from the root.
+		if supply[v] > 0 {
+			tail[a], head[a], flow[a] = v, root, supply[v]
+			pi[v] = -artCost
+		} else {
+			tail[a], head[a], flow[a] = root, v, -supply[v]
+			pi[v] = artCost
+		}
+		capacity[a], arcCost[a], state[a] = inf, artCost, simplexTree
+		parent[v], pred[v], depth[v] = root, a, 1
+		child[v] = -1
+		link(v, root)
+	}
+
+	// residual returns the capacity of the arc a to push flow from v.
+	residual := func(a, v int) T {
+		if tail[a] == v {
+			return capacity[a] - flow[a]
+		}
+		return flow[a]
+	}
+
+	// Block search pricing, look for the arc of most negative
+	// reduced cost in blocks of arcs, starting after the last one.
+	block := max(int(math.Sqrt(float64(m+n))), 10)
+	start := 0
+	entering := func() int {
+		best, bestCost, count := -1, T(0), 0
+		for i := range m + n {
+			a := (start + i) % (m + n)
+			if state[a] != simplexTree {
+				c := arcCost[a] + pi[tail[a]] - pi[head[a]]
+				if state[a] == simplexUpper {
+					c = -c
+				}
+				if c < bestCost {
+					best, bestCost = a, c
+				}
+			}
+			if count++; count == block {
+				if best != -1 {
+					start = (a + 1) % (m + n)
+					return best
+				}
+				count = 0
+			}
+		}
+		return best
+	}
+
+	var up1, up2, stack []int // the cycle arcs, from first and second to the join
+	for {
+		a := entering()
+		if a == -1 {
+			break
+		}
+
+		// Push flow along a from first to second, and back to first
+		// through the tree.
+		first, second := tail[a], head[a]
+		if state[a] == simplexUpper {
+			first, second = second, first
+		}
+		join, x := first, second
+		for join != x {
+			switch {
+			case depth[join] > depth[x]:
+				join = parent[join]
+			case depth[x] > depth[join]:
+				x = parent[x]
+			default:
+				join, x = parent[join], parent[x]
+			}
+		}
+
+		// The cycle in its orientation is join -> first, a,
+		// second -> join. The flow goes down from join to first
+		// and up from second to join.
+		delta := capacity[a]
+		up1, up2 = up1[:0], up2[:0]
+		for x := first; x != join; x = parent[x] {
+			up1 = append(up1, x)
+			delta = min(delta, residual(pred[x], parent[x]))
+		}
+		for x := second; x != join; x = parent[x] {
+			up2 = append(up2, x)
+			delta = min(delta, residual(pred[x], x))
+		}
+
+		// The leaving arc is the last blocking arc in the orientation,
+		// out is the vertex below it, or -1 if it is a.
+		out, outSecond := -1, false
+		for i := len(up1) - 1; i >= 0; i-- {
+			if x := up1[i]; residual(pred[x], parent[x]) == delta {
+				out, outSecond = x, false
+			}
+		}
+		if capacity[a] == delta {
+			out = -1
+		}
+		for _, x := range up2 {
+			if residual(pred[x], x) == delta {
+				out, outSecond = x, true
+			}
+		}
+
+		// Push delta along the cycle.
+		if state[a] == simplexLower {
+			flow[a] += delta
+		} else {
+			flow[a] -= delta
+		}
+		for _, x := range up1 {
+			if tail[pred[x]] == parent[x] {
+				flow[pred[x]] += delta
+			} else {
+				flow[pred[x]] -= delta
+			}
+		}
+		for _, x := range up2 {
+			if tail[pred[x]] == x {
+				flow[pred[x]] += delta
+			} else {
+				flow[pred[x]] -= delta
+			}
+		}
+
+		if out == -1 {
+			state[a] = -state[a]
+			continue
+		}
+
+		// Replace the leaving arc by a, the subtree below the leaving
+		// arc hangs from a, rooted at its end in the subtree.
+		l := pred[out]
+		if flow[l] == 0 {
+			state[l] = simplexLower
+		} else {
+			state[l] = simplexUpper
+		}
+		state[a] = simplexTree
+		u, p := first, second
+		if outSecond {
+			u, p = second, first
+		}
+		subtree, arc := u, a
+		for {
+			nextU, nextArc := parent[u], pred[u]
+			unlink(u, nextU)
+			parent[u], pred[u] = p, arc
+			link(u, p)
+			if u == out {
+				break
+			}
+			p, arc, u = u, nextArc, nextU
+		}
+
+		// Update the depths and the potentials of the subtree.
+		stack = append(stack[:0], subtree)
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			p, e := parent[v], pred[v]
+			depth[v] = depth[p] + 1
+			if tail[e] == p {
+				pi[v] = pi[p] + arcCost[e]
+			} else {
+				pi[v] = pi[p] - arcCost[e]
+			}
+			for c := child[v]; c != -1; c = next[c] {
+				stack = append(stack, c)
+			}
+		}
+	}
+
+	for v := range n {
+		if flow[m+v] > nw.eps {
+			return 0, ErrInfeasible
+		}
+	}
+	for i, a := range ids {
+		if flow[i] > 0 {
+			nw.push(a, flow[i])
+			cost += flow[i] * arcCost[i]
+		}
+	}
+	return cost, nil
+}
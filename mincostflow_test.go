@@ -0,0 +1,274 @@
+package grafo
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var minCostFlowAlgorithms = []MinCostFlowAlgorithm{SuccessiveShortestPaths, NetworkSimplex}
+
+func TestMinCostFlow(t *testing.T) {
+	t.Run("assignment", func(t *testing.T) {
+		// Assign the jobs 0, 1, 2 to the machines 3, 4, 5,
+		// cost[i][j] is the cost of the job i on the machine j.
+		cost := [][]int{
+			{4, 1, 3},
+			{2, 0, 5},
+			{3, 2, 2},
+		}
+		g := NewMutable[CapacityCost[int]](6)
+		supply := []int{1, 1, 1, -1, -1, -1}
+		for i := range 3 {
+			for j := range 3 {
+				g.Add(i, 3+j, CapacityCost[int]{Capacity: 1, Cost: cost[i][j]})
+			}
+		}
+		for _, a := range minCostFlowAlgorithms {
+			c, res, err := MinCostFlow(g, supply, WithMinCostFlowAlgorithm(a))
+			if err != nil {
+				t.Fatalf("MinCostFlow(%v): %v", a, err)
+			}
+			if c != 5 {
+				t.Errorf("MinCostFlow(%v) cost = %d, want 5", a, c)
+			}
+			if diff := cmp.Diff(String(res), "6 [(0 4):1 (1 3):1 (2 5):1]"); diff != "" {
+				t.Errorf("MinCostFlow(%v) graph %s", a, diff)
+			}
+		}
+	})
+
+	t.Run("circulation", func(t *testing.T) {
+		g := NewMutable[CapacityCost[float64]](4)
+		g.Add(0, 1, CapacityCost[float64]{Capacity: 2, Cost: -3})
+		g.Add(1, 2, CapacityCost[float64]{Capacity: 5, Cost: 1})
+		g.Add(2, 0, CapacityCost[float64]{Capacity: 1.5, Cost: 1})
+		g.Add(2, 3, CapacityCost[float64]{Capacity: 5, Cost: 0.5})
+		g.Add(3, 0, CapacityCost[float64]{Capacity: 5, Cost: 0.5})
+		for _, a := range minCostFlowAlgorithms {
+			c, res, err := MinCostFlow(g, nil, WithMinCostFlowAlgorithm(a))
+			if err != nil {
+				t.Fatalf("MinCostFlow(%v): %v", a, err)
+			}
+			if c != -2 {
+				t.Errorf("MinCostFlow(%v) cost = %v, want -2", a, c)
+			}
+			if diff := cmp.Diff(String(res), "4 [(0 1):2 (1 2):2 (2 0):1.5 (2 3):0.5 (3 0):0.5]"); diff != "" {
+				t.Errorf("MinCostFlow(%v) graph %s", a, diff)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		g := NewMutable[CapacityCost[int]](3)
+		g.Add(0, 1, CapacityCost[int]{Capacity: 2, Cost: 1})
+		g.Add(2, 1, CapacityCost[int]{Capacity: 2, Cost: 1})
+		for _, a := range minCostFlowAlgorithms {
+			opt := WithMinCostFlowAlgorithm(a)
+			if _, _, err := MinCostFlow(g, []int{1, 0, 0}, opt); !errors.Is(err, ErrInfeasible) {
+				t.Errorf("MinCostFlow(%v) unbalanced supplies: %v, want ErrInfeasible", a, err)
+			}
+			if _, _, err := MinCostFlow(g, []int{3, -3, 0}, opt); !errors.Is(err, ErrInfeasible) {
+				t.Errorf("MinCostFlow(%v) over capacity: %v, want ErrInfeasible", a, err)
+			}
+			if _, _, err := MinCostFlow(g, []int{1, 0, -1}, opt); !errors.Is(err, ErrInfeasible) {
+				t.Errorf("MinCostFlow(%v) unreachable demand: %v, want ErrInfeasible", a, err)
+			}
+		}
+		g.Add(1, 0, CapacityCost[int]{Capacity: InfFor[int](), Cost: -2})
+		if _, _, err := MinCostFlow(g, nil); !errors.Is(err, ErrUnbounded) {
+			t.Errorf("MinCostFlow negative cycle: %v, want ErrUnbounded", err)
+		}
+
+		h := NewMutable[CapacityCost[int8]](3)
+		h.Add(0, 1, CapacityCost[int8]{Capacity: 1, Cost: 10})
+		h.Add(1, 2, CapacityCost[int8]{Capacity: 1, Cost: 1})
+		opt := WithMinCostFlowAlgorithm(NetworkSimplex)
+		if _, _, err := MinCostFlow(h, []int8{1, 0, -1}, opt); !errors.Is(err, ErrCostOverflow) {
+			t.Errorf("MinCostFlow large costs: %v, want ErrCostOverflow", err)
+		}
+		if c, _, err := MinCostFlow(h, []int8{1, 0, -1}); err != nil || c != 11 {
+			t.Errorf("MinCostFlow large costs = %d, %v, want 11", c, err)
+		}
+	})
+
+	t.Run("epsilon", func(t *testing.T) {
+		g := NewMutable[CapacityCost[float64]](3)
+		g.Add(0, 2, CapacityCost[float64]{Capacity: 1, Cost: 1})
+		g.Add(1, 2, CapacityCost[float64]{Capacity: 1, Cost: 2})
+		// The supplies don't add up to 0 in floating-point.
+		supply := []float64{0.1, 0.2, -0.3}
+		for _, a := range minCostFlowAlgorithms {
+			if _, _, err := MinCostFlow(g, supply, WithMinCostFlowAlgorithm(a)); !errors.Is(err, ErrInfeasible) {
+				t.Errorf("MinCostFlow(%v) = %v, want ErrInfeasible", a, err)
+			}
+			c, _, err := MinCostFlow(g, supply, WithMinCostFlowAlgorithm(a), WithMinCostFlowEpsilon(1e-9))
+			if err != nil {
+				t.Fatalf("MinCostFlow(%v) with epsilon: %v", a, err)
+			}
+			if math.Abs(c-0.5) > 1e-9 {
+				t.Errorf("MinCostFlow(%v) with epsilon cost = %v, want 0.5", a, c)
+			}
+		}
+	})
+
+	t.Run("brute force", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 200 {
+			g, supply := randomCostFlow(2+rnd.IntN(3), 6, 2, rnd)
+			want, feasible := bruteForceMinCostFlow(g, supply)
+			for _, a := range minCostFlowAlgorithms {
+				c, res, err := MinCostFlow(g, supply, WithMinCostFlowAlgorithm(a))
+				if !feasible {
+					if !errors.Is(err, ErrInfeasible) {
+						t.Fatalf("MinCostFlow(%v, %v, %v) = %v, want ErrInfeasible", a, edgeList(g), supply, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("MinCostFlow(%v, %v, %v): %v", a, edgeList(g), supply, err)
+				}
+				if c != want {
+					t.Fatalf("MinCostFlow(%v, %v, %v) = %d, want %d", a, edgeList(g), supply, c, want)
+				}
+				testCostFlow(t, g, supply, c, res)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 2 + rnd.IntN(50)
+			g, supply := randomCostFlow(n, rnd.IntN(min(5*n, n*(n-1))), 20, rnd)
+			want, _, wantErr := MinCostFlow(g, supply)
+			c, res, err := MinCostFlow(g, supply, WithMinCostFlowAlgorithm(NetworkSimplex))
+			if err != wantErr {
+				t.Fatalf("MinCostFlow errors %v and %v", wantErr, err)
+			}
+			if err != nil {
+				continue
+			}
+			if c != want {
+				t.Fatalf("MinCostFlow costs %d and %d", want, c)
+			}
+			testCostFlow(t, g, supply, c, res)
+		}
+	})
+}
+
+func TestMinCostMaxFlow(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 50 {
+		n := 2 + rnd.IntN(50)
+		g, _ := randomCostFlow(n, rnd.IntN(min(5*n, n*(n-1))), 20, rnd)
+		s, tt := rnd.IntN(n), rnd.IntN(n)
+		if s == tt {
+			continue
+		}
+		want, _ := MaxFlow(capacities[int]{g}, s, tt, WithMaxFlowAlgorithm(Dinic))
+		var costs []int
+		for _, a := range minCostFlowAlgorithms {
+			flow, c, res, err := MinCostMaxFlow(g, s, tt, WithMinCostFlowAlgorithm(a))
+			if err != nil {
+				t.Fatalf("MinCostMaxFlow(%v): %v", a, err)
+			}
+			if flow != want {
+				t.Fatalf("MinCostMaxFlow(%v) flow = %d, want %d", a, flow, want)
+			}
+			supply := make([]int, n)
+			supply[s], supply[tt] = flow, -flow
+			testCostFlow(t, g, supply, c, res)
+			costs = append(costs, c)
+		}
+		if costs[0] != costs[1] {
+			t.Fatalf("MinCostMaxFlow costs %v", costs)
+		}
+	}
+}
+
+// randomCostFlow returns a random graph with n vertices and at most m edges
+// of capacities and costs up to maxW, and the supplies of a feasible flow,
+// if any.
+func randomCostFlow(n, m, maxW int, rnd *rand.Rand) (*Mutable[CapacityCost[int]], []int) {
+	g := NewMutable[CapacityCost[int]](n)
+	for range m {
+		v, w := rnd.IntN(n), rnd.IntN(n)
+		if v != w {
+			g.Add(v, w, CapacityCost[int]{Capacity: rnd.IntN(maxW + 1), Cost: rnd.IntN(2*maxW+1) - maxW})
+		}
+	}
+	// Make the supplies of a random flow.
+	supply := make([]int, n)
+	for v := range n {
+		for w, e := range g.EdgesFrom(v) {
+			f := rnd.IntN(e.Capacity + 1)
+			if rnd.IntN(2) == 0 {
+				f = 0
+			}
+			supply[v] += f
+			supply[w] -= f
+		}
+	}
+	if rnd.IntN(5) == 0 {
+		supply[rnd.IntN(n)]++
+		supply[rnd.IntN(n)]--
+	}
+	return g, supply
+}
+
+// bruteForceMinCostFlow tries all the flows of g.
+func bruteForceMinCostFlow(g *Mutable[CapacityCost[int]], supply []int) (cost int, feasible bool) {
+	edges := edgeList(g)
+	balance := make([]int, g.Order())
+	var try func(i, c int)
+	try = func(i, c int) {
+		if i == len(edges) {
+			for v, b := range balance {
+				if b != supply[v] {
+					return
+				}
+			}
+			if !feasible || c < cost {
+				cost, feasible = c, true
+			}
+			return
+		}
+		e := edges[i]
+		for f := range e.Weight.Capacity + 1 {
+			balance[e.V] += f
+			balance[e.W] -= f
+			try(i+1, c+f*e.Weight.Cost)
+			balance[e.V] -= f
+			balance[e.W] += f
+		}
+	}
+	try(0, 0)
+	return cost, feasible
+}
+
+// testCostFlow checks that res is a flow of cost c within the capacities
+// of g that satisfies the supplies.
+func testCostFlow(t *testing.T, g *Mutable[CapacityCost[int]], supply []int, c int, res Graph[int]) {
+	t.Helper()
+	balance := make([]int, g.Order())
+	sum := 0
+	for _, e := range edgeList(res) {
+		w := g.Weight(e.V, e.W)
+		if e.Weight > w.Capacity {
+			t.Fatalf("flow %d of (%d %d) exceeds its capacity %d", e.Weight, e.V, e.W, w.Capacity)
+		}
+		balance[e.V] += e.Weight
+		balance[e.W] -= e.Weight
+		sum += e.Weight * w.Cost
+	}
+	if diff := cmp.Diff(balance, supply); diff != "" {
+		t.Fatalf("flow doesn't satisfy the supplies %s", diff)
+	}
+	if sum != c {
+		t.Fatalf("flow costs %d, want %d", sum, c)
+	}
+}
@@ -0,0 +1,187 @@
+package grafo
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// MinCut computes a minimum cut between s and t in a graph with
+// nonnegative edge capacities, from a maximum flow from s to t.
+// The vertices in source are the vertices on the side of s, that can
+// be reached from s in the residual graph, in increasing order.
+// The edges are the edges from the side of s to the side of t, all
+// saturated by the flow, with their capacities as weights. The parallel
+// edges from v to w are merged into a single edge with the sum of
+// their capacities.
+// The number cut is the sum of their capacities, it equals the
+// maximum flow. If s equals t or there is a path of edges of infinite
+// capacity from s to t, there is no cut and cut is inf.
+// (inf is +inf for floats and the maximum value for integers).
+//
+// The options are the same as in MaxFlow, the flow is computed on
+// an array-based residual graph and the parallel edges of g
+// add their capacities.
+func MinCut[T IntegerOrFloat](g Graph[T], s, t int, opts ...MaxFlowOption) (cut T, source []int, edges []Edge[T]) {
+	o := newMaxFlowOptions(opts)
+	nw := newFlowNetwork(g)
+	nw.eps = epsilon[T](o.epsilon)
+	if _, finite := nw.maxFlow(s, t, o.algorithm); !finite {
+		return InfFor[T](), nil, nil
+	}
+
+	side := nw.reachable(s)
+	// at[w] is the index in edges of the edge from the current
+	// vertex to w, or -1.
+	at := make([]int, nw.order())
+	for v := range at {
+		at[v] = -1
+	}
+	for v := range nw.order() {
+		if !side[v] {
+			continue
+		}
+		source = append(source, v)
+		first := len(edges)
+		for a := nw.start[v]; a < nw.start[v+1]; a++ {
+			w := nw.head[a]
+			if side[w] || !(nw.orig[a] > 0) {
+				continue
+			}
+			if i := at[w]; i != -1 {
+				edges[i].Weight = addInf(edges[i].Weight, nw.orig[a])
+			} else {
+				at[w] = len(edges)
+				edges = append(edges, Edge[T]{V: v, W: w, Weight: nw.orig[a]})
+			}
+			cut = addInf(cut, nw.orig[a])
+		}
+		for _, e := range edges[first:] {
+			at[e.W] = -1
+		}
+	}
+	return cut, source, edges
+}
+
+// GlobalMinCut computes a minimum cut of an undirected graph, a set of
+// edges of minimum total weight whose removal disconnects the graph,
+// using the Stoer-Wagner algorithm.
+// Each undirected edge v - w should be in g in both directions,
+// v -> w and w -> v with the same weight. Only edges with positive
+// and non-NaN weights are included.
+// The vertices in side are the vertices of one of the two parts of the
+// cut, in increasing order, and cut is the sum of the weights of the
+// edges between the parts, 0 if the graph isn't connected.
+// If g has fewer than 2 vertices there is no cut, cut is inf and side is nil.
+// (inf is +inf for floats and the maximum value for integers).
+//
+// The time complexity is O(|V|⋅(|E| + |V|)⋅log|V|), where |E| is the number of
+// edges and |V| the number of vertices in the graph.
+func GlobalMinCut[T IntegerOrFloat](g Graph[T]) (cut T, side []int) {
+	n := g.Order()
+	cut = InfFor[T]()
+	if n < 2 {
+		return cut, nil
+	}
+	// adj[v] has the weights of the edges from v, the vertices
+	// are merged into a single vertex after each phase.
+	// The slices keep the order of the edges, so the ties between
+	// the cuts are always broken the same way.
+	adj := make([][]neighbor[T], n)
+	members := make([][]int, n)
+	alive := make([]int, n)
+	at := make([]int, n) // at[w] is the index of the edge to w being added to, or -1
+	for v := range n {
+		at[v] = -1
+	}
+	for v := range n {
+		members[v] = []int{v}
+		alive[v] = v
+		for w, weight := range g.EdgesFrom(v) {
+			if v != w && weight > 0 {
+				adj[v] = addNeighbor(adj[v], at, w, weight)
+			}
+		}
+		for _, e := range adj[v] {
+			at[e.vertex] = -1
+		}
+	}
+
+	key := make([]T, n)
+	added := make([]bool, n)
+	Q := &semiringQueue[T]{
+		index: make([]int, n),
+		dist:  key,
+		less:  func(a, b T) bool { return a > b },
+	}
+	for len(alive) > 1 {
+		// Add the vertices in maximum adjacency order, the most
+		// tightly connected to the vertices added first.
+		for _, v := range alive {
+			key[v], added[v] = 0, false
+			heap.Push(Q, v)
+		}
+		prev, last := -1, -1
+		for Q.Len() > 0 {
+			u := heap.Pop(Q).(int)
+			added[u] = true
+			prev, last = last, u
+			for _, e := range adj[u] {
+				if x := e.vertex; !added[x] {
+					key[x] = addInf(key[x], e.weight)
+					heap.Fix(Q, Q.index[x])
+				}
+			}
+		}
+
+		// The cut of the phase separates last from the others.
+		if side == nil || key[last] < cut {
+			cut, side = key[last], slices.Clone(members[last])
+		}
+
+		// Merge last into prev.
+		members[prev] = append(members[prev], members[last]...)
+		isLast := func(e neighbor[T]) bool { return e.vertex == last }
+		adj[prev] = slices.DeleteFunc(adj[prev], isLast)
+		for i, e := range adj[prev] {
+			at[e.vertex] = i
+		}
+		for _, e := range adj[last] {
+			if x := e.vertex; x != prev {
+				adj[prev] = addNeighbor(adj[prev], at, x, e.weight)
+				adj[x] = slices.DeleteFunc(adj[x], isLast)
+				adj[x] = addEdgeWeight(adj[x], prev, e.weight)
+			}
+		}
+		for _, e := range adj[prev] {
+			at[e.vertex] = -1
+		}
+		adj[last], members[last] = nil, nil
+		alive = slices.DeleteFunc(alive, func(v int) bool { return v == last })
+	}
+	slices.Sort(side)
+	return cut, side
+}
+
+// addNeighbor adds weight to the edge to w in edges, or appends it
+// if there is none. at[x] is the index of the edge to x in edges,
+// or -1 if there is none, it is updated with the appended edge.
+func addNeighbor[T IntegerOrFloat](edges []neighbor[T], at []int, w int, weight T) []neighbor[T] {
+	if i := at[w]; i != -1 {
+		edges[i].weight = addInf(edges[i].weight, weight)
+		return edges
+	}
+	at[w] = len(edges)
+	return append(edges, neighbor[T]{w, weight})
+}
+
+// addEdgeWeight adds weight to the edge to w in edges,
+// or appends it if there is none.
+func addEdgeWeight[T IntegerOrFloat](edges []neighbor[T], w int, weight T) []neighbor[T] {
+	for i := range edges {
+		if edges[i].vertex == w {
+			edges[i].weight = addInf(edges[i].weight, weight)
+			return edges
+		}
+	}
+	return append(edges, neighbor[T]{w, weight})
+}
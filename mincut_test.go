@@ -0,0 +1,205 @@
+package grafo
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rschio/grafo/internal/multigraph"
+)
+
+func TestMinCut(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		g := NewMutable[int](4)
+		g.Add(0, 1, 3)
+		g.Add(0, 2, 2)
+		g.Add(1, 2, 5)
+		g.Add(1, 3, 1)
+		g.Add(2, 3, 3)
+		g.Add(3, 0, 7)
+		cut, source, edges := MinCut(g, 0, 3)
+		if cut != 4 {
+			t.Errorf("MinCut cut = %d, want 4", cut)
+		}
+		if diff := cmp.Diff(source, []int{0, 1, 2}); diff != "" {
+			t.Errorf("MinCut source %s", diff)
+		}
+		want := []Edge[int]{{1, 3, 1}, {2, 3, 3}}
+		if diff := cmp.Diff(edges, want); diff != "" {
+			t.Errorf("MinCut edges %s", diff)
+		}
+	})
+
+	t.Run("parallel edges", func(t *testing.T) {
+		g := multigraph.New[int](3)
+		g.Add(0, 1, 2)
+		g.Add(0, 1, 3)
+		g.Add(0, 2, 1)
+		g.Add(0, 2, 1)
+		g.Add(1, 2, 9)
+		cut, source, edges := MinCut(g, 0, 2)
+		if cut != 7 {
+			t.Errorf("MinCut cut = %d, want 7", cut)
+		}
+		if diff := cmp.Diff(source, []int{0}); diff != "" {
+			t.Errorf("MinCut source %s", diff)
+		}
+		want := []Edge[int]{{0, 1, 5}, {0, 2, 2}}
+		if diff := cmp.Diff(edges, want); diff != "" {
+			t.Errorf("MinCut edges %s", diff)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 2 + rnd.IntN(100)
+			g := generateRandom(n, rnd.IntN(min(5*n, n*(n-1))), 50, rnd)
+			s, tt := rnd.IntN(n), rnd.IntN(n)
+			if s == tt {
+				continue
+			}
+			want, _ := MaxFlow(g, s, tt)
+			for _, a := range []MaxFlowAlgorithm{EdmondsKarp, Dinic, PushRelabel} {
+				cut, source, edges := MinCut(g, s, tt, WithMaxFlowAlgorithm(a))
+				if cut != want {
+					t.Fatalf("MinCut(%d, %d, %v) = %d, want %d", s, tt, a, cut, want)
+				}
+				side := make([]bool, n)
+				for _, v := range source {
+					side[v] = true
+				}
+				if !side[s] || side[tt] {
+					t.Fatalf("MinCut(%d, %d, %v) source %v doesn't separate s and t", s, tt, a, source)
+				}
+				sum := 0
+				for _, e := range edges {
+					if !side[e.V] || side[e.W] || g.Weight(e.V, e.W) != e.Weight {
+						t.Fatalf("MinCut(%d, %d, %v) wrong cut edge %v", s, tt, a, e)
+					}
+					sum += e.Weight
+				}
+				if sum != cut {
+					t.Fatalf("MinCut(%d, %d, %v) edges weigh %d, want %d", s, tt, a, sum, cut)
+				}
+			}
+		}
+	})
+}
+
+func TestGlobalMinCut(t *testing.T) {
+	t.Run("stoer-wagner", func(t *testing.T) {
+		// The example of the paper "A Simple Min-Cut Algorithm".
+		g := NewMutable[int](8)
+		for _, e := range []Edge[int]{
+			{0, 1, 2}, {0, 4, 3}, {1, 2, 3}, {1, 4, 2}, {1, 5, 2},
+			{2, 3, 4}, {2, 6, 2}, {3, 6, 2}, {3, 7, 2}, {4, 5, 3},
+			{5, 6, 1}, {6, 7, 3},
+		} {
+			g.AddBoth(e.V, e.W, e.Weight)
+		}
+		cut, side := GlobalMinCut(g)
+		if cut != 4 {
+			t.Errorf("GlobalMinCut cut = %d, want 4", cut)
+		}
+		if side[0] == 0 {
+			side = complement(8, side)
+		}
+		if diff := cmp.Diff(side, []int{2, 3, 6, 7}); diff != "" {
+			t.Errorf("GlobalMinCut side %s", diff)
+		}
+	})
+
+	t.Run("small", func(t *testing.T) {
+		cut, side := GlobalMinCut(NewMutable[float64](1))
+		if cut != InfFor[float64]() || side != nil {
+			t.Errorf("GlobalMinCut = %v, %v, want inf, nil", cut, side)
+		}
+		cut, side = GlobalMinCut(NewMutable[float64](2))
+		if cut != 0 || len(side) != 1 {
+			t.Errorf("GlobalMinCut = %v, %v, want 0 and a vertex", cut, side)
+		}
+	})
+
+	t.Run("ties", func(t *testing.T) {
+		// Each vertex linked to the 2 next and the 2 previous
+		// vertices of a cycle is alone a minimum cut.
+		m := NewMutable[int](8)
+		for v := range 8 {
+			for _, d := range []int{1, 2} {
+				m.Add(v, (v+d)%8, 1)
+				m.Add((v+d)%8, v, 1)
+			}
+		}
+		g := Sort(m)
+		_, want := GlobalMinCut(g)
+		for range 20 {
+			if _, side := GlobalMinCut(g); !slices.Equal(side, want) {
+				t.Fatalf("GlobalMinCut side = %v, then %v", want, side)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 100 {
+			n := 2 + rnd.IntN(9)
+			g := NewMutable[int](n)
+			for range rnd.IntN(n * n) {
+				v, w := rnd.IntN(n), rnd.IntN(n)
+				g.AddBoth(v, w, rnd.IntN(10))
+			}
+			cut, side := GlobalMinCut(g)
+			if got := cutWeight(g, side); got != cut {
+				t.Fatalf("GlobalMinCut side %v weighs %d, want %d", side, got, cut)
+			}
+			if len(side) == 0 || len(side) == n {
+				t.Fatalf("GlobalMinCut side %v isn't a cut", side)
+			}
+			// Try all the cuts.
+			for mask := 1; mask < 1<<(n-1); mask++ {
+				var s []int
+				for v := range n {
+					if mask&(1<<v) != 0 {
+						s = append(s, v)
+					}
+				}
+				if w := cutWeight(g, s); w < cut {
+					t.Fatalf("GlobalMinCut = %d, but %v weighs %d", cut, s, w)
+				}
+			}
+		}
+	})
+}
+
+// cutWeight returns the weight of the edges from side to the other vertices.
+func cutWeight(g *Mutable[int], side []int) int {
+	in := make([]bool, g.Order())
+	for _, v := range side {
+		in[v] = true
+	}
+	sum := 0
+	for _, v := range side {
+		for w, weight := range g.EdgesFrom(v) {
+			if !in[w] {
+				sum += weight
+			}
+		}
+	}
+	return sum
+}
+
+func complement(n int, side []int) []int {
+	in := make([]bool, n)
+	for _, v := range side {
+		in[v] = true
+	}
+	var c []int
+	for v := range n {
+		if !in[v] {
+			c = append(c, v)
+		}
+	}
+	return c
+}
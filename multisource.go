@@ -0,0 +1,78 @@
+package grafo
+
+// MultiSourceShortestPaths computes the shortest paths from the nearest
+// of the sources to all other vertices, using Dijkstra's algorithm
+// started from all the sources at once. It partitions the vertices by
+// their nearest source, as a Voronoi diagram of the graph.
+//
+// The path from the source sources[i] starts with the length offsets[i],
+// like the cost to open a facility, or 0 if offsets is nil.
+// The sources with a NaN offset are skipped. MultiSourceShortestPaths panics
+// if offsets isn't nil and has a different length from sources.
+//
+// Only edges with non-negative and non-NaN costs are included.
+// The number parent[w] is the predecessor of w on a shortest path from
+// the nearest source to w, or -1 if w is that source or cannot be reached.
+// The number dist[w] equals the length of that path plus the offset of its
+// source, or is inf if w cannot be reached.
+// (inf is +inf for floats and the maximum value for integers).
+// The number origin[w] is the nearest source to w, or -1 if w cannot
+// be reached. A source is reached from another one if that is nearer
+// with the offsets.
+//
+// The time complexity is O((|E| + |V|)⋅log|V|), where |E| is the number of edges
+// and |V| the number of vertices in the graph.
+func MultiSourceShortestPaths[T IntegerOrFloat](g Graph[T], sources []int, offsets []T) (parent []int, dist []T, origin []int) {
+	if offsets != nil && len(offsets) != len(sources) {
+		panic("grafo: MultiSourceShortestPaths offsets and sources have different lengths")
+	}
+	n := g.Order()
+	parent = make([]int, n)
+	dist = make([]T, n)
+	origin = make([]int, n)
+	inf := InfFor[T]()
+	for i := range n {
+		parent[i], dist[i], origin[i] = -1, inf, -1
+	}
+
+	Q := emptyPrioQueue(dist)
+	for i, v := range sources {
+		var offset T
+		if offsets != nil {
+			offset = offsets[i]
+		}
+		if isNaN(offset) {
+			continue
+		}
+		switch {
+		case origin[v] == -1:
+			dist[v], origin[v] = offset, v
+			Q.Push(v)
+		case offset < dist[v]:
+			dist[v], origin[v] = offset, v
+			Q.Fix(v)
+		}
+	}
+
+	// Dijkstra's algorithm, origin[w] is -1 until w is reached.
+	for Q.Len() > 0 {
+		v := Q.Pop()
+		for w, weight := range g.EdgesFrom(v) {
+			// Skip NaN and negative edges.
+			if isNaN(weight) || weight < 0 {
+				continue
+			}
+			alt := addInf(dist[v], weight)
+			switch {
+			case origin[w] == -1:
+				dist[w], parent[w], origin[w] = alt, v, origin[v]
+				Q.Push(w)
+			case alt < dist[w]:
+				dist[w], parent[w], origin[w] = alt, v, origin[v]
+				Q.Fix(w)
+			}
+		}
+	}
+
+	return parent, dist, origin
+}
@@ -0,0 +1,87 @@
+package grafo
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMultiSourceShortestPaths(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(500)
+			g := generateRandomWithRand(n, rnd.IntN(5*n), func() int { return rnd.IntN(100) }, rnd)
+			sources := make([]int, 1+rnd.IntN(5))
+			offsets := make([]int, len(sources))
+			for i := range sources {
+				sources[i], offsets[i] = rnd.IntN(n), rnd.IntN(200)
+			}
+
+			inf := InfFor[int]()
+			want := make([]int, n)
+			for i := range want {
+				want[i] = inf
+			}
+			for i, v := range sources {
+				_, dist := ShortestPaths(g, v)
+				for w, d := range dist {
+					if d != inf {
+						want[w] = min(want[w], d+offsets[i])
+					}
+				}
+			}
+
+			parent, dist, origin := MultiSourceShortestPaths(g, sources, offsets)
+			if diff := cmp.Diff(dist, want); diff != "" {
+				t.Fatalf("MultiSourceShortestPaths(%v, %v) dist %s", sources, offsets, diff)
+			}
+			for w := range n {
+				if dist[w] == inf {
+					if parent[w] != -1 || origin[w] != -1 {
+						t.Fatalf("unreachable %d has parent %d and origin %d", w, parent[w], origin[w])
+					}
+					continue
+				}
+				if p := parent[w]; p == -1 {
+					if origin[w] != w {
+						t.Fatalf("origin[%d] = %d, want %d", w, origin[w], w)
+					}
+				} else if origin[w] != origin[p] || addInf(dist[p], edgeCost(g, p, w)) != dist[w] {
+					t.Fatalf("parent[%d] = %d is not on a shortest path from %d", w, p, origin[w])
+				}
+			}
+		}
+	})
+
+	t.Run("offsets", func(t *testing.T) {
+		g := NewMutable[float64](6)
+		g.AddBoth(0, 1, 1)
+		g.AddBoth(1, 2, 1)
+		g.AddBoth(2, 3, 1)
+		g.AddBoth(3, 4, 1)
+		g.Add(5, 0, 1)
+
+		parent, dist, origin := MultiSourceShortestPaths(g, []int{0, 4, 4}, []float64{2.5, 1, math.NaN()})
+		inf := math.Inf(1)
+		if diff := cmp.Diff(dist, []float64{2.5, 3.5, 3, 2, 1, inf}); diff != "" {
+			t.Errorf("MultiSourceShortestPaths->dist %s", diff)
+		}
+		if diff := cmp.Diff(parent, []int{-1, 0, 3, 4, -1, -1}); diff != "" {
+			t.Errorf("MultiSourceShortestPaths->parent %s", diff)
+		}
+		if diff := cmp.Diff(origin, []int{0, 0, 4, 4, 4, -1}); diff != "" {
+			t.Errorf("MultiSourceShortestPaths->origin %s", diff)
+		}
+
+		_, dist, origin = MultiSourceShortestPaths(g, []int{0, 4}, nil)
+		if diff := cmp.Diff(dist, []float64{0, 1, 2, 1, 0, inf}); diff != "" {
+			t.Errorf("MultiSourceShortestPaths(nil)->dist %s", diff)
+		}
+		if origin[2] != 0 && origin[2] != 4 {
+			t.Errorf("MultiSourceShortestPaths(nil)->origin[2] = %d", origin[2])
+		}
+	})
+}
@@ -41,6 +41,11 @@ import (
 // and |V| the number of vertices in the graph.
 func ShortestPath[T IntegerOrFloat](g Graph[T], v, w int) (path []int, dist T) {
 	parent, distances := shortestPath(g, v, w)
+	return pathTo(parent, distances, w)
+}
+
+// pathTo returns the path to w following parent and its distance.
+func pathTo[T IntegerOrFloat](parent []int, distances []T, w int) (path []int, dist T) {
 	path, dist = []int{}, distances[w]
 	// dist can be inf when w is unreachable or if there is a path
 	// of inifinity cost.
@@ -0,0 +1,251 @@
+package grafo
+
+import (
+	"container/heap"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Semiring describes how the weights of the edges are combined into
+// the weight of a path, for the shortest path algorithms with weights
+// of any type, like ShortestPathsSemiring.
+//
+// The weight of a path is the Extend of the weights of its edges,
+// starting from One, and a shortest path is the best path by Less.
+type Semiring[W any] struct {
+	// Zero is the weight of the vertices that can't be reached.
+	Zero W
+	// One is the weight of the empty path, from a vertex to itself.
+	One W
+	// Extend returns the weight of a path of weight a
+	// followed by an edge of weight b.
+	Extend func(a, b W) W
+	// Less tells whether the weight a is better than b.
+	Less func(a, b W) bool
+}
+
+// Combine returns the best of a and b, a if they are equal.
+func (s Semiring[W]) Combine(a, b W) W {
+	if s.Less(b, a) {
+		return b
+	}
+	return a
+}
+
+// MinPlus returns the semiring of the usual shortest paths, where the
+// weight of a path is the sum of the weights of its edges, inf if it
+// overflows, and the best path is the shortest.
+// (inf is +inf for floats and the maximum value for integers).
+// Unlike in ShortestPath the NaN edges aren't skipped, a path with
+// a NaN edge weights NaN, which is worse than any other weight.
+func MinPlus[T IntegerOrFloat]() Semiring[T] {
+	return Semiring[T]{
+		Zero:   InfFor[T](),
+		One:    0,
+		Extend: add[T],
+		Less:   func(a, b T) bool { return a < b || isNaN(b) && !isNaN(a) },
+	}
+}
+
+// MaxMin returns the semiring of the widest paths, where the weight of
+// a path is the minimum weight of its edges, like the capacity of the
+// path, and the best path is the widest.
+func MaxMin[T IntegerOrFloat]() Semiring[T] {
+	return Semiring[T]{
+		Zero:   negInfFor[T](),
+		One:    InfFor[T](),
+		Extend: func(a, b T) T { return min(a, b) },
+		Less:   func(a, b T) bool { return a > b },
+	}
+}
+
+// MaxTimes returns the semiring of the most reliable paths, where the
+// weight of a path is the product of the weights of its edges, like
+// the probability of crossing the path, and the best path is the most
+// reliable. The weights should be between 0 and 1.
+func MaxTimes[T constraints.Float]() Semiring[T] {
+	return Semiring[T]{
+		Zero:   0,
+		One:    1,
+		Extend: func(a, b T) T { return a * b },
+		Less:   func(a, b T) bool { return a > b },
+	}
+}
+
+// negInfFor returns -inf for floats and the minimum value for integers.
+func negInfFor[T IntegerOrFloat]() T {
+	if isFloat[T]() {
+		return -InfFor[T]()
+	}
+	return InfFor[T]() + 1
+}
+
+// ShortestPathSemiring computes a best path from v to w in the
+// semiring s, using Dijkstra's algorithm.
+// The edges that make a path better, Extend(a, weight) better than a,
+// are skipped, as the negative edges in ShortestPath.
+// The weight dist is the weight of the path, or s.Zero if w
+// cannot be reached.
+//
+// The time complexity is O((|E| + |V|)⋅log|V|), where |E| is the number of edges
+// and |V| the number of vertices in the graph.
+func ShortestPathSemiring[W any](g Graph[W], s Semiring[W], v, w int) (path []int, dist W) {
+	parent, distances := shortestPathSemiring(g, s, v, w)
+	path, dist = []int{}, distances[w]
+	if v != w && parent[w] == -1 {
+		return path, dist
+	}
+	for x := w; x != -1; x = parent[x] {
+		path = append(path, x)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist
+}
+
+// ShortestPathsSemiring computes the best paths from v to all other
+// vertices in the semiring s, using Dijkstra's algorithm.
+// The edges that make a path better, Extend(a, weight) better than a,
+// are skipped, as the negative edges in ShortestPaths.
+// The number parent[w] is the predecessor of w on a best path from v to w,
+// or -1 if none exists.
+// The weight dist[w] is the weight of a best path from v to w,
+// or s.Zero if w cannot be reached.
+//
+// The time complexity is O((|E| + |V|)⋅log|V|), where |E| is the number of edges
+// and |V| the number of vertices in the graph.
+func ShortestPathsSemiring[W any](g Graph[W], s Semiring[W], v int) (parent []int, dist []W) {
+	return shortestPathSemiring(g, s, v, -1)
+}
+
+func shortestPathSemiring[W any](g Graph[W], s Semiring[W], v, w int) (parent []int, dist []W) {
+	n := g.Order()
+	dist = make([]W, n)
+	parent = make([]int, n)
+	for i := range dist {
+		dist[i], parent[i] = s.Zero, -1
+	}
+	dist[v] = s.One
+	parent[v] = v
+	defer func(v int) { parent[v] = -1 }(v)
+
+	Q := &semiringQueue[W]{index: make([]int, n), dist: dist, less: s.Less}
+	heap.Push(Q, v)
+
+	target := w
+	for Q.Len() > 0 {
+		v = heap.Pop(Q).(int)
+		if v == target {
+			return parent, dist
+		}
+		for w, weight := range g.EdgesFrom(v) {
+			alt := s.Extend(dist[v], weight)
+			if s.Less(alt, dist[v]) {
+				continue
+			}
+			switch {
+			case parent[w] == -1:
+				dist[w], parent[w] = alt, v
+				heap.Push(Q, w)
+			case s.Less(alt, dist[w]):
+				dist[w], parent[w] = alt, v
+				// A visited vertex is reached by a better
+				// path if Extend isn't monotone.
+				if i := Q.index[w]; i >= 0 {
+					heap.Fix(Q, i)
+				} else {
+					heap.Push(Q, w)
+				}
+			}
+		}
+	}
+	return parent, dist
+}
+
+// BellmanFordSemiring computes the best paths from v to all other
+// vertices in the semiring s, like BellmanFord it allows edges
+// that make a path better.
+// The results have the same meaning as in ShortestPathsSemiring,
+// ok is false if there is a cycle reachable from v that makes
+// a path better.
+func BellmanFordSemiring[W any](g Graph[W], s Semiring[W], v int) (parent []int, dist []W, ok bool) {
+	n := g.Order()
+	parent = make([]int, n)
+	dist = make([]W, n)
+	reached := make([]bool, n)
+	onQueue := make([]bool, n)
+	for i := range n {
+		parent[i], dist[i] = -1, s.Zero
+	}
+	dist[v], reached[v] = s.One, true
+	Q := newQueue(n)
+	Q.Push(v)
+	onQueue[v] = true
+
+	sentinel := n
+	Q.Push(sentinel)
+
+	k := 0
+	for {
+		v = Q.Pop()
+		if v < sentinel {
+			onQueue[v] = false
+			for w, weight := range g.EdgesFrom(v) {
+				alt := s.Extend(dist[v], weight)
+				if reached[w] && !s.Less(alt, dist[w]) {
+					continue
+				}
+				dist[w], parent[w], reached[w] = alt, v, true
+				if !onQueue[w] {
+					Q.Push(w)
+					onQueue[w] = true
+				}
+			}
+			continue
+		}
+		if Q.Len() == 0 {
+			return parent, dist, true
+		}
+		k++
+		if k >= n {
+			return parent, dist, false
+		}
+		Q.Push(sentinel)
+	}
+}
+
+// semiringQueue is a priority queue of vertices by their dist,
+// it implements heap.Interface.
+type semiringQueue[W any] struct {
+	heap  []int // vertices in heap order
+	index []int // index of each vertex in the heap
+	dist  []W
+	less  func(a, b W) bool
+}
+
+func (q *semiringQueue[W]) Len() int { return len(q.heap) }
+
+func (q *semiringQueue[W]) Less(i, j int) bool {
+	return q.less(q.dist[q.heap[i]], q.dist[q.heap[j]])
+}
+
+func (q *semiringQueue[W]) Swap(i, j int) {
+	q.heap[i], q.heap[j] = q.heap[j], q.heap[i]
+	q.index[q.heap[i]] = i
+	q.index[q.heap[j]] = j
+}
+
+func (q *semiringQueue[W]) Push(x any) {
+	v := x.(int)
+	q.index[v] = len(q.heap)
+	q.heap = append(q.heap, v)
+}
+
+func (q *semiringQueue[W]) Pop() any {
+	n := len(q.heap) - 1
+	v := q.heap[n]
+	q.index[v] = -1
+	q.heap = q.heap[:n]
+	return v
+}
@@ -0,0 +1,140 @@
+package grafo
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShortestPathsSemiring(t *testing.T) {
+	t.Run("MinPlus", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(100)
+			g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+			v := rnd.IntN(n)
+			wantParent, wantDist := ShortestPaths(g, v)
+			_, dist := ShortestPathsSemiring(g, MinPlus[int](), v)
+			if diff := cmp.Diff(dist, wantDist); diff != "" {
+				t.Fatalf("ShortestPathsSemiring(%s, %d)->dist %s", String(g), v, diff)
+			}
+			w := rnd.IntN(n)
+			path, d := ShortestPathSemiring(g, MinPlus[int](), v, w)
+			if d != wantDist[w] {
+				t.Fatalf("ShortestPathSemiring(%s, %d, %d) got dist %d, want %d", String(g), v, w, d, wantDist[w])
+			}
+			if wantParent[w] == -1 && v != w {
+				if len(path) != 0 {
+					t.Fatalf("ShortestPathSemiring(%s, %d, %d) got path %v, want []", String(g), v, w, path)
+				}
+				continue
+			}
+			if path[0] != v || path[len(path)-1] != w || pathLength(g, path) != d {
+				t.Fatalf("ShortestPathSemiring(%s, %d, %d) got path %v, want a path of length %d", String(g), v, w, path, d)
+			}
+		}
+	})
+
+	t.Run("MaxMin", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(30)
+			g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+			v := rnd.IntN(n)
+			_, dist := ShortestPathsSemiring(g, MaxMin[int](), v)
+			for w := range n {
+				// The width to w is the largest c such that w is
+				// reachable from v with the edges of weight >= c.
+				want := math.MinInt
+				if w == v {
+					want = math.MaxInt
+				}
+				for c := range 20 {
+					if w != v && reachable(widerThan(g, c), v, w) {
+						want = c
+					}
+				}
+				if dist[w] != want {
+					t.Fatalf("ShortestPathsSemiring(%s, MaxMin, %d) got dist[%d] = %d, want %d", String(g), v, w, dist[w], want)
+				}
+			}
+		}
+	})
+
+	t.Run("MaxTimes", func(t *testing.T) {
+		rnd := rand.New(rand.NewPCG(1, 2))
+		for range 50 {
+			n := 1 + rnd.IntN(50)
+			g := generateRandomWithRand(n, rnd.IntN(3*n), rnd.Float64, rnd)
+			// The most reliable path is the shortest
+			// with weights -log(p).
+			logG := NewMutable[float64](n)
+			for v := range n {
+				for w, p := range g.EdgesFrom(v) {
+					if q, ok := logG.edges[v][w]; !ok || -math.Log(p) < q {
+						logG.Add(v, w, -math.Log(p))
+					}
+				}
+			}
+			v := rnd.IntN(n)
+			_, dist := ShortestPathsSemiring(g, MaxTimes[float64](), v)
+			_, want := ShortestPaths(logG, v)
+			for w := range n {
+				if got := -math.Log(dist[w]); math.Abs(got-want[w]) > 1e-9 && !(math.IsInf(got, 1) && math.IsInf(want[w], 1)) {
+					t.Fatalf("ShortestPathsSemiring(MaxTimes, %d) got dist[%d] = %v, want %v", v, w, dist[w], math.Exp(-want[w]))
+				}
+			}
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		g := NewMutable[float64](3)
+		g.Add(0, 1, math.NaN())
+		g.Add(0, 2, 1)
+		g.Add(2, 1, 1)
+		_, dist := ShortestPathsSemiring(g, MinPlus[float64](), 0)
+		if diff := cmp.Diff(dist, []float64{0, 2, 1}); diff != "" {
+			t.Errorf("ShortestPathsSemiring->dist %s", diff)
+		}
+	})
+}
+
+// widerThan returns the graph with the edges of g of weight >= c.
+func widerThan(g *Mutable[int], c int) *Mutable[int] {
+	h := NewMutable[int](g.Order())
+	for v := range g.Order() {
+		for w, weight := range g.EdgesFrom(v) {
+			if weight >= c {
+				h.Add(v, w, weight)
+			}
+		}
+	}
+	return h
+}
+
+func TestBellmanFordSemiring(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for range 100 {
+		n := 1 + rnd.IntN(30)
+		g := generateRandom(n, rnd.IntN(n*(n-1)+1), 20, rnd)
+		for v := range n {
+			for w, weight := range g.EdgesFrom(v) {
+				g.Add(v, w, weight-3)
+			}
+		}
+		v := rnd.IntN(n)
+		_, wantDist, wantOK := BellmanFord(g, v)
+		_, dist, ok := BellmanFordSemiring(g, MinPlus[int](), v)
+		if ok != wantOK {
+			t.Fatalf("BellmanFordSemiring(%s, %d) got ok = %v, want %v", String(g), v, ok, wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if diff := cmp.Diff(dist, wantDist); diff != "" {
+			t.Fatalf("BellmanFordSemiring(%s, %d)->dist %s", String(g), v, diff)
+		}
+	}
+}